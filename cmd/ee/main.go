@@ -9,14 +9,24 @@ import (
 
 	"github.com/n1rna/ee-cli/internal/command"
 	"github.com/n1rna/ee-cli/internal/config"
+	"github.com/n1rna/ee-cli/internal/logger"
+	"github.com/n1rna/ee-cli/internal/output"
 	"github.com/n1rna/ee-cli/internal/util"
 )
 
+// Build metadata, set via -ldflags "-X main.version=... -X main.commit=... -X main.date=..."
+var (
+	version = "0.11.0"
+	commit  string
+	date    string
+)
+
 var (
-	version     = "0.11.0"
 	cfgFile     string
 	globalFlags = struct {
-		debug bool
+		debug   bool
+		color   string
+		noColor bool
 	}{}
 )
 
@@ -30,6 +40,18 @@ func main() {
 
 	// Set up persistent pre-run for command context initialization
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		colorMode := output.ColorMode(globalFlags.color)
+		if globalFlags.noColor {
+			colorMode = output.ColorNever
+		}
+		if err := output.ConfigureColor(colorMode); err != nil {
+			return err
+		}
+
+		if globalFlags.debug {
+			logger.EnableDebug(os.Stderr)
+		}
+
 		// Load configuration from environment
 		cfg, err := config.LoadConfig()
 		if err != nil {
@@ -40,6 +62,11 @@ func main() {
 		if cfgFile != "" {
 			cfg.ConfigFile = cfgFile
 		}
+		logger.Debugf("using base dir %s", cfg.BaseDir)
+		if cfg.SettingsLoadError != nil {
+			// Stderr keeps structured stdout output parseable
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v (using default settings)\n", cfg.SettingsLoadError)
+		}
 
 		// Initialize command context (includes project detection)
 		commandContext, err := util.NewCommandContext(cfg)
@@ -47,6 +74,11 @@ func main() {
 			return fmt.Errorf("failed to initialize command context: %w", err)
 		}
 
+		// Explicit --format flags win over $EE_FORMAT and output.format
+		if err := command.ApplyDefaultFormat(cmd, cfg.Settings.DefaultFormat()); err != nil {
+			return fmt.Errorf("failed to apply default format: %w", err)
+		}
+
 		ctx := command.WithCommandContext(cmd.Context(), commandContext)
 		cmd.SetContext(ctx)
 		return nil
@@ -56,6 +88,10 @@ func main() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "",
 		"Path to project config file (default: .ee in current directory)")
 	rootCmd.PersistentFlags().BoolVar(&globalFlags.debug, "debug", false, "Enable debug output")
+	rootCmd.PersistentFlags().StringVar(&globalFlags.color, "color", string(output.ColorAuto),
+		"Color output: auto (only on a terminal), always, never")
+	rootCmd.PersistentFlags().BoolVar(&globalFlags.noColor, "no-color", false,
+		"Disable color output (same as --color never)")
 
 	// Add command groups
 	rootCmd.AddGroup(&cobra.Group{
@@ -74,8 +110,15 @@ func main() {
 		command.NewApplyCommand("global"),   // Apply environment variables
 		command.NewHydrateCommand("global"), // Generate env file from schema + shell env
 		command.NewVerifyCommand("global"),  // Verify project configuration
+		command.NewSchemaCommand("global"),  // Export/import the project schema
+		command.NewConfigCommand("global"),  // View and set user settings
 		command.NewSkillCommand("global"),   // Install ee usage guide for AI coding agents
 		command.NewAuthCommand("global"),    // Authentication
+		command.NewVersionCommand("global", command.BuildInfo{
+			Version: version,
+			Commit:  commit,
+			Date:    date,
+		}), // Build metadata
 
 		// Remote Operations - push secrets to origins
 		command.NewPushCommand("authenticated"),
@@ -2,15 +2,25 @@
 package command
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/n1rna/ee-cli/internal/config"
+	"github.com/n1rna/ee-cli/internal/entities"
+	"github.com/n1rna/ee-cli/internal/fsutil"
 	"github.com/n1rna/ee-cli/internal/output"
 	"github.com/n1rna/ee-cli/internal/parser"
 	"github.com/n1rna/ee-cli/internal/util"
@@ -48,17 +58,114 @@ Examples:
 
   # Show what would be applied without executing
   ee apply development --dry-run
+
+  # Annotate each value with the source it came from
+  ee apply development --dry-run --explain
+
+  # Merge extra .env files on top of an environment (later files win)
+  ee apply development --env-file .env.local --env-file .env.secret -- npm start
+
+  # Write the merged environment to a file instead of starting a shell
+  ee apply development --output .env.generated
+
+  # Head the generated file with a "generated by ee" comment block
+  ee apply development --output .env.generated --comment-banner
+
+  # List required variables first, in schema order
+  ee apply development --output .env.generated --sort-by-schema
+
+  # Write every project environment to ./out/<env>.env
+  ee apply --all-environments --export-dir ./out
+
+  # Restart the dev server whenever the environment's .env files change
+  ee apply development --watch -- npm start
+
+  # Export into the current shell, refusing values the shell would expand
+  eval "$(ee apply production --dry-run -q --eval-safe)"
+
+  # Capture a single resolved value in a script
+  DB_URL=$(ee apply production --get DATABASE_URL)
+
+  # Start a background service with the environment and print its PID
+  ee apply production --detach --log-file server.log -- ./server
+
+  # Pass the whole environment as one JSON-encoded variable
+  ee apply production --json-env APP_CONFIG --json-only -- node server.js
+
+  # Layer extra variables from stdin on top of the environment
+  printf 'API_TOKEN=%s\n' "$TOKEN" | ee apply production --env-from-stdin -- ./deploy.sh
 `,
-		Args:    cobra.MinimumNArgs(1),
-		RunE:    ac.Run,
-		GroupID: groupId,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all, _ := cmd.Flags().GetBool("all-environments"); all {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		RunE:              ac.Run,
+		ValidArgsFunction: completeEnvironmentNames,
+		GroupID:           groupId,
 	}
 
 	cmd.Flags().BoolP("dry-run", "d", false,
 		"Show what would be applied without executing")
+	cmd.Flags().Bool("explain", false,
+		"Annotate each dry-run value with the source it came from (env and dotenv formats)")
 	cmd.Flags().StringP("format", "f", "env",
-		"Output format for dry-run (env, dotenv, json)")
+		"Output format for dry-run (env, dotenv, json, yaml, csv)")
+	cmd.Flags().Bool("reveal", false,
+		"Show secret values in dry-run json, yaml and csv output (masked as **** by default)")
+	annotateFormats(cmd, "env", "dotenv", "json", "yaml", "csv")
 	cmd.Flags().BoolP("quiet", "q", false, "Suppress informational output")
+	cmd.Flags().StringP("output", "o", "",
+		"Write resolved values to a file instead of running a shell or command")
+	cmd.Flags().Bool("detach", false,
+		"Run the command in the background in a new session and print its PID")
+	cmd.Flags().String("log-file", "",
+		"With --detach, append the command's output to this file (default: discard)")
+	cmd.Flags().Bool("no-trailing-newline", false,
+		"Omit the newline after the last line of env/dotenv output")
+	cmd.Flags().Bool("sort-by-schema", false,
+		"Order env/dotenv output by the schema: required variables first, then optional, then the rest")
+	cmd.Flags().Bool("null-as-empty", true,
+		"Keep variables with empty values as KEY=\"\"; --null-as-empty=false leaves them out entirely")
+	cmd.Flags().Bool("comment-banner", false,
+		"Start env/dotenv output with a comment naming the sources and generation time")
+	cmd.Flags().Bool("eval-safe", false,
+		"Refuse env/dotenv output with values containing $, ` or \\, which a shell would expand when eval'd")
+	cmd.Flags().Bool("only-secrets", false,
+		"Keep only secret variables (schema secret flag or sensitive-looking name)")
+	cmd.Flags().Bool("no-secrets", false,
+		"Drop secret variables (schema secret flag or sensitive-looking name)")
+	cmd.Flags().String("get", "",
+		"Print only the resolved value of this variable, by schema name (fails if it is not set)")
+	cmd.Flags().String("prefix", "",
+		"Prepend this prefix to every variable name (e.g. APP_)")
+	cmd.Flags().String("strip-prefix", "",
+		"Remove this prefix from variable names that have it")
+	cmd.Flags().Bool("require-clean", false,
+		"Refuse to apply if the resolved values fail schema validation")
+	cmd.Flags().Bool("strict", false,
+		"Alias for --require-clean: abort before launching if any required variable is missing or invalid")
+	cmd.Flags().Bool("fail-on-extra", false,
+		"Refuse to apply if the resolved values contain variables the schema does not define")
+	cmd.Flags().Bool("watch", false,
+		"Restart the command whenever the environment's .env files change")
+	cmd.Flags().Bool("all-environments", false,
+		"Resolve every project environment and write each to --export-dir")
+	cmd.Flags().String("export-dir", "",
+		"Directory for --all-environments output files (<env>.env)")
+	cmd.Flags().StringArray("env-file", []string{},
+		"Additional .env file to merge on top (repeatable, later files override earlier ones)")
+	cmd.Flags().Bool("interpolate", false,
+		"Expand ${VAR} references in .env files, from earlier keys in the same file")
+	cmd.Flags().Bool("interpolate-env", false,
+		"Like --interpolate, but fall back to the shell environment for undefined references")
+	cmd.Flags().String("json-env", "",
+		"Also inject all values JSON-encoded into this single variable (e.g. APP_CONFIG)")
+	cmd.Flags().Bool("json-only", false,
+		"With --json-env, inject only the JSON-encoded variable")
+	cmd.Flags().Bool("env-from-stdin", false,
+		"Read extra KEY=VALUE lines from stdin, overriding resolved values")
 
 	return cmd
 }
@@ -74,7 +181,88 @@ func (c *ApplyCommand) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if all, _ := cmd.Flags().GetBool("all-environments"); all {
+		// Every environment is always validated, so --require-clean and
+		// --strict are accepted; per-invocation shaping flags are not
+		for _, name := range allEnvironmentsConflicts {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s cannot be combined with --all-environments", name)
+			}
+		}
+		exportDir, _ := cmd.Flags().GetString("export-dir")
+		return c.exportAllEnvironments(context, exportDir, printer)
+	}
+
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	explain, _ := cmd.Flags().GetBool("explain")
+	outputFile, _ := cmd.Flags().GetString("output")
+	envFiles, _ := cmd.Flags().GetStringArray("env-file")
+	envFromStdin, _ := cmd.Flags().GetBool("env-from-stdin")
+	interpolate := interpolateNone
+	if on, _ := cmd.Flags().GetBool("interpolate"); on {
+		interpolate = interpolateFile
+	}
+	if on, _ := cmd.Flags().GetBool("interpolate-env"); on {
+		interpolate = interpolateEnv
+	}
+	jsonEnv, _ := cmd.Flags().GetString("json-env")
+	jsonOnly, _ := cmd.Flags().GetBool("json-only")
+	detach, _ := cmd.Flags().GetBool("detach")
+	logFile, _ := cmd.Flags().GetString("log-file")
+	watch, _ := cmd.Flags().GetBool("watch")
+	noTrailingNewline, _ := cmd.Flags().GetBool("no-trailing-newline")
+	evalSafe, _ := cmd.Flags().GetBool("eval-safe")
+	commentBanner, _ := cmd.Flags().GetBool("comment-banner")
+	nullAsEmpty, _ := cmd.Flags().GetBool("null-as-empty")
+	sortBySchema, _ := cmd.Flags().GetBool("sort-by-schema")
+	onlySecrets, _ := cmd.Flags().GetBool("only-secrets")
+	noSecrets, _ := cmd.Flags().GetBool("no-secrets")
+	requireClean, _ := cmd.Flags().GetBool("require-clean")
+	if strict, _ := cmd.Flags().GetBool("strict"); strict {
+		requireClean = true
+	}
+	failOnExtra, _ := cmd.Flags().GetBool("fail-on-extra")
+	prefix, _ := cmd.Flags().GetString("prefix")
+	getKey, _ := cmd.Flags().GetString("get")
+	stripPrefix, _ := cmd.Flags().GetString("strip-prefix")
+	reveal, _ := cmd.Flags().GetBool("reveal")
+
+	// Structured formats must not be interleaved with informational output;
+	// --get output is meant for $(...) capture and must stay undecorated too
+	structured := format == "json" || format == "yaml" || format == "csv" || getKey != ""
+
+	if explain && (!dryRun || structured) {
+		return fmt.Errorf("--explain requires --dry-run with the env or dotenv format")
+	}
+	if jsonOnly && jsonEnv == "" {
+		return fmt.Errorf("--json-only requires --json-env")
+	}
+	if watch && detach {
+		return fmt.Errorf("--watch cannot be combined with --detach")
+	}
+	if onlySecrets && noSecrets {
+		return fmt.Errorf("--only-secrets and --no-secrets are mutually exclusive")
+	}
+	if getKey != "" && (outputFile != "" || detach || watch) {
+		return fmt.Errorf("--get cannot be combined with --output, --detach or --watch")
+	}
+	if evalSafe && format != "env" && format != "dotenv" {
+		return fmt.Errorf("--eval-safe requires the env or dotenv format")
+	}
+	if commentBanner && format != "env" && format != "dotenv" {
+		return fmt.Errorf("--comment-banner requires the env or dotenv format")
+	}
+	if sortBySchema && format != "env" && format != "dotenv" {
+		return fmt.Errorf("--sort-by-schema requires the env or dotenv format")
+	}
+
+	var secrets map[string]bool
+	if onlySecrets || noSecrets {
+		secrets, err = projectSecrets(context)
+		if err != nil {
+			return err
+		}
+	}
 
 	envOrFile := args[0]
 	var commandArgs []string
@@ -87,45 +275,242 @@ func (c *ApplyCommand) Run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	var values map[string]string
-
-	// Detect if the argument is a file path or environment name
-	if isFilePath(envOrFile) {
-		values, err = c.applyEnvFile(envOrFile)
+	// Stdin can only be consumed once, so read it up front and layer it on
+	// every resolution (including reloads in --watch mode)
+	var stdinValues map[string]string
+	if envFromStdin {
+		stdinValues, err = c.readEnvFromStdin(cmd.InOrStdin())
 		if err != nil {
 			return err
 		}
-		if !quiet && format != "json" {
+	}
+
+	// resolve merges the base environment or file, --env-file layers and stdin
+	// values into the final values and their sources, validating them for
+	// --require-clean and --fail-on-extra before the secret filters apply
+	resolve := func() (map[string]string, map[string]string, error) {
+		var values map[string]string
+		var err error
+		sources := make(map[string]string)
+
+		// Detect if the argument is a file path or environment name
+		if isFilePath(envOrFile) {
+			values, err = c.applyEnvFile(envOrFile, interpolate)
+			if err != nil {
+				return nil, nil, err
+			}
+			recordSources(sources, values, envOrFile)
+
+			if local, exists := util.LocalOverridePath(envOrFile); exists && localOverrides(context) {
+				localValues, err := c.applyEnvFile(local, interpolate)
+				if err != nil {
+					return nil, nil, err
+				}
+				values = layerValues(values, localValues)
+				recordSources(sources, localValues, local)
+			}
+		} else {
+			values, sources, err = c.applyProjectEnvironment(context, envOrFile, interpolate)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// Merge additional .env files left-to-right on top of the base values
+		for _, envFile := range envFiles {
+			fileValues, err := c.applyEnvFile(envFile, interpolate)
+			if err != nil {
+				return nil, nil, err
+			}
+			values = layerValues(values, fileValues)
+			recordSources(sources, fileValues, envFile)
+		}
+
+		if envFromStdin {
+			values = layerValues(values, stdinValues)
+			recordSources(sources, stdinValues, "stdin")
+		}
+
+		if stripPrefix != "" {
+			values, sources = renameKeys(values, sources, stripPrefix, "")
+		}
+
+		// Validate the full set before --only-secrets/--no-secrets drops
+		// required variables, and before --prefix renames keys away from
+		// their schema names
+		if requireClean {
+			if err := c.requireClean(context, values, printer); err != nil {
+				return nil, nil, err
+			}
+		}
+		if failOnExtra {
+			if err := c.failOnExtra(context, values); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if onlySecrets || noSecrets {
+			values = partitionSecrets(values, secrets, onlySecrets)
+		}
+
+		return values, sources, nil
+	}
+
+	// finalize shapes the resolved (schema-named) values for export: dropping
+	// empty values for --null-as-empty=false, adding --prefix and injecting
+	// --json-env
+	finalize := func(values, sources map[string]string) (map[string]string, map[string]string, error) {
+		var err error
+		if !nullAsEmpty {
+			values = omitEmpty(values)
+		}
+		if prefix != "" {
+			values, sources = renameKeys(values, sources, "", prefix)
+		}
+
+		if jsonEnv != "" {
+			values, err = injectJSONEnv(values, jsonEnv, jsonOnly)
+			if err != nil {
+				return nil, nil, err
+			}
+			if jsonOnly {
+				sources = map[string]string{}
+			}
+			sources[jsonEnv] = "--json-env"
+		}
+
+		return values, sources, nil
+	}
+
+	values, sources, err := resolve()
+	if err != nil {
+		return err
+	}
+
+	// --get takes the schema name, so look it up before --prefix renames it
+	if getKey != "" {
+		value, ok := values[getKey]
+		if !ok {
+			return fmt.Errorf("variable '%s' is not set in '%s'", getKey, envOrFile)
+		}
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), value)
+		return err
+	}
+
+	values, sources, err = finalize(values, sources)
+	if err != nil {
+		return err
+	}
+
+	if !quiet && !structured {
+		if isFilePath(envOrFile) {
+			printer.Info(fmt.Sprintf("Applying .env file: %s", envOrFile))
+		} else {
+			printer.Info(fmt.Sprintf("Applying environment '%s'", envOrFile))
+		}
+		if envFromStdin {
 			printer.Info(fmt.Sprintf(
-				"Applying .env file: %s", envOrFile,
+				"Applied %d variables from stdin", len(stdinValues),
 			))
 		}
-	} else {
-		values, err = c.applyProjectEnvironment(context, envOrFile)
+	}
+
+	precedence := []string{envOrFile}
+	precedence = append(precedence, envFiles...)
+	if envFromStdin {
+		precedence = append(precedence, "stdin")
+	}
+
+	export := exportOptions{noTrailingNewline: noTrailingNewline}
+	if commentBanner {
+		export.banner = generatedBanner(envOrFile, precedence, time.Now())
+	}
+	if sortBySchema {
+		export.order, err = schemaOrder(context, prefix)
 		if err != nil {
 			return err
 		}
-		if !quiet && format != "json" {
-			printer.Info(fmt.Sprintf(
-				"Applying environment '%s'", envOrFile,
-			))
+	}
+
+	// Output meant for eval must not smuggle in command substitution
+	if evalSafe && (dryRun || outputFile != "") {
+		if err := checkEvalSafe(values); err != nil {
+			return err
 		}
 	}
 
 	if dryRun {
-		if format != "json" && !quiet {
+		if !structured && !quiet {
+			if len(precedence) > 1 {
+				printer.Info(fmt.Sprintf(
+					"Source precedence (later overrides earlier): %s",
+					strings.Join(precedence, " < "),
+				))
+			}
 			printer.Info("Environment variables that would be applied:")
 		}
-		switch format {
-		case "env":
-			return printer.PrintEnvironmentExport(values)
-		case "dotenv":
-			return printer.PrintDotEnv(values)
-		case "json":
-			return printer.PrintValues(values)
-		default:
-			return fmt.Errorf("unsupported format: %s", format)
+		// Informational lines above keep their newlines; only the values do not
+		export.configure(printer)
+		// Only the structured formats mask secrets; env and dotenv are for export
+		if !reveal && structured {
+			if err := maskProjectSecrets(printer, context, prefix); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(),
+					"Warning: could not load secret variables, values are not masked: %v\n", err)
+			}
+		}
+		printer.PrintCommentBanner(export.banner)
+		if explain {
+			return c.printExplained(printer, format, values, sources)
+		}
+		return c.printValues(printer, format, values)
+	}
+
+	if outputFile != "" {
+		// Files consumed by other tools expect dotenv unless a format is given
+		fileFormat := format
+		if !cmd.Flags().Changed("format") {
+			fileFormat = "dotenv"
+		}
+		if err := c.writeOutputFile(outputFile, fileFormat, values, export); err != nil {
+			return err
+		}
+		printer.Success(fmt.Sprintf("Wrote %d variables to %s", len(values), outputFile))
+		return nil
+	}
+
+	if detach {
+		if len(commandArgs) == 0 {
+			return fmt.Errorf("--detach requires a command after --")
+		}
+		pid, err := c.startDetached(values, commandArgs, logFile)
+		if err != nil {
+			return err
+		}
+		printer.Success(fmt.Sprintf("Started %s in the background (PID %d)", commandArgs[0], pid))
+		return nil
+	}
+
+	if watch {
+		if len(commandArgs) == 0 {
+			return fmt.Errorf("--watch requires a command after --")
 		}
+		files, err := c.watchedFiles(context, envOrFile, envFiles)
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		reload := func() (map[string]string, error) {
+			// With --require-clean a broken environment fails here, keeping
+			// the running process rather than restarting into it
+			values, sources, err := resolve()
+			if err != nil {
+				return nil, err
+			}
+			values, _, err = finalize(values, sources)
+			return values, err
+		}
+		return c.runWithWatch(ctx, values, reload, files, commandArgs, printer)
 	}
 
 	// Apply environment variables
@@ -135,57 +520,264 @@ func (c *ApplyCommand) Run(cmd *cobra.Command, args []string) error {
 	return c.startShellWithEnvironment(values, printer)
 }
 
-// applyProjectEnvironment applies a project environment using the .ee file
+// applyProjectEnvironment applies a project environment using the .ee file,
+// expanding ${VAR} references in its .env files as selected by interpolate
 func (c *ApplyCommand) applyProjectEnvironment(
 	context *util.CommandContext,
 	envName string,
-) (map[string]string, error) {
+	interpolate interpolation,
+) (map[string]string, map[string]string, error) {
 	if !context.IsInProject {
-		return nil, fmt.Errorf(
+		return nil, nil, fmt.Errorf(
 			"no %s file found - not in a project context",
 			config.ProjectConfigFileName,
 		)
 	}
 
 	if !context.HasEnvironment(envName) {
-		return nil, fmt.Errorf(
+		return nil, nil, fmt.Errorf(
 			"environment '%s' not found in project", envName,
 		)
 	}
 
 	envDef, err := context.GetEnvironment(envName)
 	if err != nil {
-		return nil, fmt.Errorf(
+		return nil, nil, fmt.Errorf(
 			"failed to get environment definition: %w", err,
 		)
 	}
 
+	envSources := util.EnvironmentSources{
+		Env:     envDef.Env,
+		Sources: envDef.Sources,
+		Sheets:  envDef.Sheets,
+
+		LocalOverrides: context.ProjectConfig.LocalOverrides,
+		Defaults:       expandedDefaults(context),
+	}
+	if interpolate != interpolateNone {
+		envSources.ParseFile = func(path string) (map[string]string, error) {
+			return c.applyEnvFile(path, interpolate)
+		}
+	}
+
 	resolver := util.NewEnvResolver()
-	values, err := resolver.MergeEnvironment(
-		util.EnvironmentSources{
-			Env:     envDef.Env,
-			Sources: envDef.Sources,
-			Sheets:  envDef.Sheets,
-		},
-	)
+	values, sources, err := resolver.MergeEnvironmentWithProvenance(envSources)
 	if err != nil {
-		return nil, fmt.Errorf(
+		return nil, nil, fmt.Errorf(
 			"failed to resolve environment '%s': %w", envName, err,
 		)
 	}
 
-	return values, nil
+	return values, sources, nil
+}
+
+// allEnvironmentsConflicts lists the apply flags that only make sense for a
+// single environment and are rejected with --all-environments
+var allEnvironmentsConflicts = []string{
+	"dry-run", "explain", "output", "get", "detach", "watch",
+	"env-file", "env-from-stdin", "interpolate", "interpolate-env",
+	"prefix", "strip-prefix", "only-secrets", "no-secrets", "json-env", "json-only",
+	"eval-safe", "fail-on-extra", "sort-by-schema", "comment-banner", "null-as-empty",
 }
 
-// applyEnvFile reads and parses a .env file
+// exportAllEnvironments resolves every project environment, validates it
+// against the project schema (when there is one) and writes it to
+// <exportDir>/<env>.env. A failing environment is reported and skipped so the
+// rest of the batch is still written.
+func (c *ApplyCommand) exportAllEnvironments(
+	context *util.CommandContext,
+	exportDir string,
+	printer *output.Printer,
+) error {
+	if exportDir == "" {
+		return fmt.Errorf("--all-environments requires --export-dir")
+	}
+	if err := context.RequireProjectContext(); err != nil {
+		return err
+	}
+
+	variables, err := loadSchemaVariables(context)
+	if errors.Is(err, errNoSchema) {
+		variables = nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(exportDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	envNames := context.GetEnvironmentNames()
+	sort.Strings(envNames)
+
+	start := time.Now()
+	var summary output.OperationSummary
+	for _, envName := range envNames {
+		values, _, err := c.applyProjectEnvironment(context, envName, interpolateNone)
+		if err == nil {
+			if problems := validateValues(variables, values); len(problems) > 0 {
+				for _, problem := range problems {
+					printer.Error(fmt.Sprintf("%s: %s", envName, problem))
+				}
+				err = fmt.Errorf("%d validation error(s), not written", len(problems))
+			}
+		}
+		if err == nil {
+			path := filepath.Join(exportDir, envName+".env")
+			_, statErr := os.Stat(path)
+			err = c.writeOutputFile(path, "dotenv", values, exportOptions{})
+			if err == nil {
+				if statErr == nil {
+					summary.Updated++
+				} else {
+					summary.Created++
+				}
+				printer.Success(fmt.Sprintf("%s: wrote %d variables to %s", envName, len(values), path))
+				continue
+			}
+		}
+		summary.Failed++
+		printer.Error(fmt.Sprintf("%s: %v", envName, err))
+	}
+	summary.Duration = time.Since(start)
+
+	if err := printer.PrintSummary(summary); err != nil {
+		return err
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("failed to export %d of %d environments", summary.Failed, len(envNames))
+	}
+	return nil
+}
+
+// requireClean validates values against the project schema (required, type,
+// regex, bounds) and fails listing every problem if any check does not pass
+func (c *ApplyCommand) requireClean(
+	context *util.CommandContext,
+	values map[string]string,
+	printer *output.Printer,
+) error {
+	if !context.IsInProject {
+		return fmt.Errorf("--require-clean needs a project schema (no %s file found)",
+			config.ProjectConfigFileName)
+	}
+
+	variables, err := loadSchemaVariables(context)
+	if err != nil {
+		return err
+	}
+
+	problems := validateValues(variables, values)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	for _, problem := range problems {
+		printer.Error(problem)
+	}
+	return fmt.Errorf("refusing to apply: %d validation error(s)", len(problems))
+}
+
+// failOnExtra refuses values with keys the project schema does not define,
+// catching typo'd variable names before they reach the environment
+func (c *ApplyCommand) failOnExtra(context *util.CommandContext, values map[string]string) error {
+	if !context.IsInProject {
+		return fmt.Errorf("--fail-on-extra needs a project schema (no %s file found)",
+			config.ProjectConfigFileName)
+	}
+
+	variables, err := loadSchemaVariables(context)
+	if err != nil {
+		return err
+	}
+
+	var extra []string
+	for name := range values {
+		if _, ok := variables[name]; !ok {
+			extra = append(extra, name)
+		}
+	}
+	if len(extra) == 0 {
+		return nil
+	}
+
+	sort.Strings(extra)
+	return fmt.Errorf("refusing to apply: %d variable(s) not defined in the schema: %s",
+		len(extra), strings.Join(extra, ", "))
+}
+
+// validateValues checks every schema variable against values and returns a
+// sorted list of problems
+func validateValues(
+	variables map[string]entities.Variable,
+	values map[string]string,
+) []string {
+	validator := entities.NewValidator()
+
+	var problems []string
+	for name, variable := range variables {
+		value, ok := values[name]
+		if !ok {
+			if variable.Required {
+				problems = append(problems, fmt.Sprintf("%s: required variable is missing", name))
+			}
+			continue
+		}
+		// Empty optional values are placeholders (as written by ee init and
+		// verify --fix); like verify, leave them unchecked
+		if value == "" && !variable.Required {
+			continue
+		}
+		if err := validator.ValidateValue(&variable, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// partitionSecrets keeps only the secret values (onlySecrets) or only the
+// non-secret ones. A key is secret when the schema marks it so or its name
+// looks sensitive.
+func partitionSecrets(
+	values map[string]string,
+	secrets map[string]bool,
+	onlySecrets bool,
+) map[string]string {
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		isSecret := secrets[key] || util.IsSensitive(key)
+		if isSecret == onlySecrets {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// interpolation selects how ${VAR} references in .env files are expanded
+type interpolation int
+
+const (
+	interpolateNone interpolation = iota // values are kept literally
+	interpolateFile                      // earlier keys in the same file only
+	interpolateEnv                       // earlier keys, then the process environment
+)
+
+// applyEnvFile reads and parses a .env file, expanding ${VAR} references as
+// selected by interpolate
 func (c *ApplyCommand) applyEnvFile(
 	filePath string,
+	interpolate interpolation,
 ) (map[string]string, error) {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return nil, fmt.Errorf(".env file not found: %s", filePath)
 	}
 
 	p := parser.NewAnnotatedDotEnvParser()
+	p.Interpolate = interpolate != interpolateNone
+	p.UseProcessEnv = interpolate == interpolateEnv
 	values, _, err := p.ParseFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse .env file: %w", err)
@@ -194,6 +786,229 @@ func (c *ApplyCommand) applyEnvFile(
 	return values, nil
 }
 
+// printValues renders values in the given format
+func (c *ApplyCommand) printValues(
+	printer *output.Printer,
+	format string,
+	values map[string]string,
+) error {
+	switch format {
+	case "env":
+		return printer.PrintEnvironmentExport(values)
+	case "dotenv":
+		return printer.PrintDotEnv(values)
+	case "json", "yaml", "csv":
+		return printer.PrintValues(values)
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// printExplained renders values annotated with the source each came from
+func (c *ApplyCommand) printExplained(
+	printer *output.Printer,
+	format string,
+	values, sources map[string]string,
+) error {
+	switch format {
+	case "env":
+		return printer.PrintEnvironmentExportWithSources(values, sources)
+	case "dotenv":
+		return printer.PrintDotEnvWithSources(values, sources)
+	default:
+		return fmt.Errorf("--explain is not supported with format: %s", format)
+	}
+}
+
+// exportOptions shape the env/dotenv output of --dry-run and --output
+type exportOptions struct {
+	banner            []string
+	order             []string
+	noTrailingNewline bool
+}
+
+// configure applies the options that change how printer lays out values
+func (o exportOptions) configure(printer *output.Printer) {
+	printer.SetNoTrailingNewline(o.noTrailingNewline)
+	printer.SetKeyOrder(o.order)
+}
+
+// writeOutputFile writes values to path in the given format, shaped by
+// export. The file is replaced atomically with 0600 permissions since it
+// usually contains secrets.
+func (c *ApplyCommand) writeOutputFile(
+	path, format string,
+	values map[string]string,
+	export exportOptions,
+) error {
+	return fsutil.WriteFileAtomicFunc(path, 0o600, func(w io.Writer) error {
+		printer := output.NewPrinterWithWriter(w, output.Format(format), true)
+		export.configure(printer)
+		printer.PrintCommentBanner(export.banner)
+		if err := c.printValues(printer, format, values); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		return nil
+	})
+}
+
+// readEnvFromStdin reads KEY=VALUE lines from stdin so secrets can be passed
+// through a pipe instead of argv
+func (c *ApplyCommand) readEnvFromStdin(stdin io.Reader) (map[string]string, error) {
+	resolver := util.NewEnvResolver()
+	values, err := resolver.ParseDotEnv(stdin, "stdin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables from stdin: %w", err)
+	}
+	return values, nil
+}
+
+// schemaOrder returns the project schema's variable names, renamed with
+// prefix, with required variables first; each group keeps schema order
+func schemaOrder(context *util.CommandContext, prefix string) ([]string, error) {
+	if !context.IsInProject {
+		return nil, fmt.Errorf("--sort-by-schema needs a project schema (no %s file found)",
+			config.ProjectConfigFileName)
+	}
+
+	schema, err := loadProjectSchema(context)
+	if err != nil {
+		return nil, err
+	}
+
+	var required, optional []string
+	for _, variable := range schema.Variables {
+		if variable.Required {
+			required = append(required, prefix+variable.Name)
+		} else {
+			optional = append(optional, prefix+variable.Name)
+		}
+	}
+	return append(required, optional...), nil
+}
+
+// generatedBanner returns the --comment-banner lines for output resolved from
+// envOrFile and the given sources, in precedence order
+func generatedBanner(envOrFile string, precedence []string, generatedAt time.Time) []string {
+	return []string{
+		"Generated by ee - do not edit, changes will be overwritten",
+		"Environment: " + envOrFile,
+		"Sources: " + strings.Join(precedence, " < "),
+		"Generated at: " + generatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// evalUnsafeChars are the characters a shell still interprets inside double
+// quotes: parameter expansion, command substitution and escapes
+const evalUnsafeChars = "$`\\"
+
+// checkEvalSafe rejects values that would run or expand something when the
+// env/dotenv output is eval'd or sourced, naming the offending keys
+func checkEvalSafe(values map[string]string) error {
+	var unsafe []string
+	for key, value := range values {
+		if strings.ContainsAny(value, evalUnsafeChars) {
+			unsafe = append(unsafe, key)
+		}
+	}
+	if len(unsafe) == 0 {
+		return nil
+	}
+
+	sort.Strings(unsafe)
+	return fmt.Errorf(
+		"--eval-safe: values of %s contain $, ` or \\ and would be expanded when eval'd",
+		strings.Join(unsafe, ", "),
+	)
+}
+
+// omitEmpty returns values without the variables whose value is empty
+func omitEmpty(values map[string]string) map[string]string {
+	kept := make(map[string]string, len(values))
+	for key, value := range values {
+		if value != "" {
+			kept[key] = value
+		}
+	}
+	return kept
+}
+
+// layerValues returns base with overlay applied on top; overlay wins on conflict
+func layerValues(base, overlay map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		merged[key] = value
+	}
+	return merged
+}
+
+// recordSources marks every key in values as coming from source
+func recordSources(sources, values map[string]string, source string) {
+	for key := range values {
+		sources[key] = source
+	}
+}
+
+// renameKeys removes strip from the start of keys that have it and then
+// prepends prefix to every key, moving each key's source along with it. When
+// stripping makes two keys collide, the originally prefixed one wins.
+func renameKeys(values, sources map[string]string, strip, prefix string) (map[string]string, map[string]string) {
+	renamed := make(map[string]string, len(values))
+	renamedSources := make(map[string]string, len(sources))
+
+	rename := func(key string) (string, bool) {
+		stripped := strip != "" && strings.HasPrefix(key, strip) && len(key) > len(strip)
+		if stripped {
+			key = key[len(strip):]
+		}
+		return prefix + key, stripped
+	}
+
+	// Unprefixed keys first so stripped keys overwrite them on collision
+	for _, pass := range []bool{false, true} {
+		for key, value := range values {
+			newKey, stripped := rename(key)
+			if stripped != pass {
+				continue
+			}
+			renamed[newKey] = value
+			if source, ok := sources[key]; ok {
+				renamedSources[newKey] = source
+			}
+		}
+	}
+
+	return renamed, renamedSources
+}
+
+// injectJSONEnv encodes values as a JSON object into the variable name. When
+// only is set the result holds just that variable; otherwise it is added
+// alongside the individual values.
+func injectJSONEnv(values map[string]string, name string, only bool) (map[string]string, error) {
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode values as JSON: %w", err)
+	}
+
+	if only {
+		return map[string]string{name: string(encoded)}, nil
+	}
+	return layerValues(values, map[string]string{name: string(encoded)}), nil
+}
+
+// buildEnviron returns the current process environment with values appended,
+// so applied values take precedence over inherited ones
+func buildEnviron(values map[string]string) []string {
+	env := os.Environ()
+	for key, value := range values {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env
+}
+
 // runCommandWithEnvironment runs a command with the specified environment variables
 func (c *ApplyCommand) runCommandWithEnvironment(
 	values map[string]string,
@@ -208,10 +1023,7 @@ func (c *ApplyCommand) runCommandWithEnvironment(
 	args := commandArgs[1:]
 
 	cmd := exec.Command(cmdName, args...)
-	cmd.Env = os.Environ()
-	for key, value := range values {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
-	}
+	cmd.Env = buildEnviron(values)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -226,6 +1038,43 @@ func (c *ApplyCommand) runCommandWithEnvironment(
 	return nil
 }
 
+// startDetached starts the command in its own session with stdio redirected
+// to logFile (or the null device) and returns its PID without waiting for it
+func (c *ApplyCommand) startDetached(
+	values map[string]string,
+	commandArgs []string,
+	logFile string,
+) (int, error) {
+	outPath := logFile
+	if outPath == "" {
+		outPath = os.DevNull
+	}
+	out, err := os.OpenFile(outPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer func() {
+		_ = out.Close()
+	}()
+
+	cmd := exec.Command(commandArgs[0], commandArgs[1:]...)
+	cmd.Env = buildEnviron(values)
+	cmd.Stdin = nil
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	pid := cmd.Process.Pid
+	if err := cmd.Process.Release(); err != nil {
+		return pid, fmt.Errorf("failed to detach from command: %w", err)
+	}
+	return pid, nil
+}
+
 // startShellWithEnvironment starts a new shell with the specified environment variables
 func (c *ApplyCommand) startShellWithEnvironment(
 	values map[string]string,
@@ -247,10 +1096,7 @@ func (c *ApplyCommand) startShellWithEnvironment(
 		cmd = exec.Command(shell)
 	}
 
-	cmd.Env = os.Environ()
-	for key, value := range values {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
-	}
+	cmd.Env = buildEnviron(values)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -267,6 +1113,11 @@ func (c *ApplyCommand) startShellWithEnvironment(
 	return nil
 }
 
+// localOverrides reports whether the project opts in to <file>.local overrides
+func localOverrides(context *util.CommandContext) bool {
+	return context.IsInProject && context.ProjectConfig.LocalOverrides
+}
+
 // isFilePath detects if the argument is a file path rather than an environment name
 func isFilePath(arg string) bool {
 	if strings.HasPrefix(arg, ".") || strings.HasPrefix(arg, "/") ||
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package command
+
+import "syscall"
+
+// detachedProcAttr starts the child in a new session so it is not tied to the
+// terminal and survives the ee process exiting
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package command
+
+import "syscall"
+
+// detachedProcess is the Windows DETACHED_PROCESS creation flag, which is not
+// exported by the syscall package
+const detachedProcess = 0x00000008
+
+// detachedProcAttr starts the child without a console in its own process group
+// so it survives the ee process exiting
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}
+}
@@ -0,0 +1,1042 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/n1rna/ee-cli/internal/entities"
+	"github.com/n1rna/ee-cli/internal/output"
+	"github.com/n1rna/ee-cli/internal/parser"
+	"github.com/n1rna/ee-cli/internal/util"
+)
+
+func TestEnvFromStdinOverridesResolvedValues(t *testing.T) {
+	c := &ApplyCommand{}
+	stdin := strings.NewReader("# injected by CI\nAPI_TOKEN=from-stdin\nDEBUG=\"true\"\n")
+
+	stdinValues, err := c.readEnvFromStdin(stdin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	values := layerValues(
+		map[string]string{"API_TOKEN": "from-sheet", "PORT": "3000"},
+		stdinValues,
+	)
+
+	want := map[string]string{"API_TOKEN": "from-stdin", "DEBUG": "true", "PORT": "3000"}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("%s = %q, want %q", key, values[key], value)
+		}
+	}
+
+	// The child process environment must expose the stdin value. Later
+	// entries win when a key is duplicated, so check the last occurrence.
+	var got string
+	for _, entry := range buildEnviron(values) {
+		if strings.HasPrefix(entry, "API_TOKEN=") {
+			got = strings.TrimPrefix(entry, "API_TOKEN=")
+		}
+	}
+	if got != "from-stdin" {
+		t.Errorf("child environment API_TOKEN = %q, want %q", got, "from-stdin")
+	}
+}
+
+func TestEnvFromStdinRejectsMalformedLines(t *testing.T) {
+	c := &ApplyCommand{}
+	if _, err := c.readEnvFromStdin(strings.NewReader("NOT_A_PAIR\n")); err == nil {
+		t.Fatal("expected error for line without '='")
+	}
+}
+
+func TestEnvFilesMergeLeftToRight(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, ".env.local")
+	secret := filepath.Join(dir, ".env.secret")
+	if err := os.WriteFile(local, []byte("PORT=4000\nHOST=local\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(secret, []byte("HOST=secret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &ApplyCommand{}
+	values := map[string]string{"PORT": "3000", "NODE_ENV": "development"}
+	for _, path := range []string{local, secret} {
+		fileValues, err := c.applyEnvFile(path, interpolateNone)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		values = layerValues(values, fileValues)
+	}
+
+	want := map[string]string{"PORT": "4000", "HOST": "secret", "NODE_ENV": "development"}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("%s = %q, want %q", key, values[key], value)
+		}
+	}
+
+	if _, err := c.applyEnvFile(filepath.Join(dir, "missing"), interpolateNone); err == nil {
+		t.Fatal("expected error for missing env file")
+	}
+}
+
+func TestWriteOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env.generated")
+	c := &ApplyCommand{}
+
+	if err := c.writeOutputFile(path, "dotenv", map[string]string{"B": "2", "A": "1"}, exportOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "A=\"1\"\nB=\"2\"\n"; string(data) != want {
+		t.Errorf("got %q, want %q", string(data), want)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+}
+
+func TestInjectJSONEnv(t *testing.T) {
+	values := map[string]string{"PORT": "3000", "DEBUG": "true"}
+
+	for _, only := range []bool{false, true} {
+		injected, err := injectJSONEnv(values, "APP_CONFIG", only)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, hasPort := injected["PORT"]; hasPort == only {
+			t.Errorf("only=%v: individual variables present = %v", only, hasPort)
+		}
+
+		var encoded string
+		for _, entry := range buildEnviron(injected) {
+			if strings.HasPrefix(entry, "APP_CONFIG=") {
+				encoded = strings.TrimPrefix(entry, "APP_CONFIG=")
+			}
+		}
+
+		var decoded map[string]string
+		if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+			t.Fatalf("only=%v: APP_CONFIG is not valid JSON: %v", only, err)
+		}
+		if len(decoded) != len(values) || decoded["PORT"] != "3000" || decoded["DEBUG"] != "true" {
+			t.Errorf("only=%v: decoded %v, want %v", only, decoded, values)
+		}
+	}
+}
+
+func TestExportAllEnvironmentsCollectsErrors(t *testing.T) {
+	dir := t.TempDir()
+	devFile := filepath.Join(dir, ".env.development")
+	if err := os.WriteFile(devFile, []byte("PORT=3000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	context := &util.CommandContext{
+		IsInProject: true,
+		ProjectConfig: &parser.ProjectConfig{
+			Project: "app",
+			Environments: map[string]parser.EnvironmentDefinition{
+				"development": {Env: devFile},
+				"production":  {Env: filepath.Join(dir, ".env.production")},
+			},
+		},
+	}
+
+	exportDir := filepath.Join(dir, "out")
+	printer := output.NewPrinter(output.FormatTable, true)
+	err := (&ApplyCommand{}).exportAllEnvironments(context, exportDir, printer)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Fatalf("expected one failed environment, got %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(exportDir, "development.env"))
+	if err != nil {
+		t.Fatalf("development.env not written: %v", err)
+	}
+	if string(data) != "PORT=\"3000\"\n" {
+		t.Errorf("development.env = %q", string(data))
+	}
+	if _, err := os.Stat(filepath.Join(exportDir, "production.env")); !os.IsNotExist(err) {
+		t.Errorf("production.env should not exist, stat error: %v", err)
+	}
+}
+
+func TestExportAllEnvironmentsValidatesAgainstSchema(t *testing.T) {
+	dir := t.TempDir()
+	devFile := filepath.Join(dir, ".env.development")
+	prodFile := filepath.Join(dir, ".env.production")
+	if err := os.WriteFile(devFile, []byte("PORT=3000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(prodFile, []byte("PORT=not-a-number\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	context := &util.CommandContext{
+		IsInProject: true,
+		ProjectConfig: &parser.ProjectConfig{
+			Project: "app",
+			Schema: parser.ProjectConfigSchema{
+				Variables: map[string]entities.Variable{
+					"PORT": {Type: "number", Required: true},
+				},
+			},
+			Environments: map[string]parser.EnvironmentDefinition{
+				"development": {Env: devFile},
+				"production":  {Env: prodFile},
+			},
+		},
+	}
+
+	exportDir := filepath.Join(dir, "out")
+	var buf strings.Builder
+	printer := output.NewPrinterWithWriter(&buf, output.FormatJSON, true)
+	err := (&ApplyCommand{}).exportAllEnvironments(context, exportDir, printer)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Fatalf("expected one failed environment, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(exportDir, "development.env")); err != nil {
+		t.Errorf("development.env not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(exportDir, "production.env")); !os.IsNotExist(err) {
+		t.Errorf("invalid production.env should not be written, stat error: %v", err)
+	}
+
+	var summary struct {
+		Created int `json:"created"`
+		Failed  int `json:"failed"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &summary); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, buf.String())
+	}
+	if summary.Created != 1 || summary.Failed != 1 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestAllEnvironmentsRejectsSingleEnvironmentFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"--prefix", "APP_"},
+		{"--env-file", ".env.local"},
+		{"--only-secrets"},
+	} {
+		cmd := NewApplyCommand("")
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		cmd.SetArgs(append([]string{"--all-environments", "--export-dir", t.TempDir()}, args...))
+		cmdCtx := &util.CommandContext{}
+		err := cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx))
+		if err == nil || !strings.Contains(err.Error(), "cannot be combined with --all-environments") {
+			t.Errorf("%v: expected a conflict error, got %v", args, err)
+		}
+	}
+}
+
+func TestStartDetachedRunsCommandWithEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("detached test relies on sh")
+	}
+
+	dir := t.TempDir()
+	outFile := filepath.Join(dir, "out.txt")
+	logFile := filepath.Join(dir, "detached.log")
+
+	values := map[string]string{"FOO": "detached-value"}
+	commandArgs := []string{"sh", "-c", `printf '%s' "$FOO" > "$0"`, outFile}
+
+	pid, err := (&ApplyCommand{}).startDetached(values, commandArgs, logFile)
+	if err != nil {
+		t.Fatalf("startDetached failed: %v", err)
+	}
+	if pid <= 0 {
+		t.Fatalf("expected a positive PID, got %d", pid)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := os.ReadFile(outFile)
+		if err == nil && string(data) == "detached-value" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("detached command did not write expected output (data=%q, err=%v)", data, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if _, err := os.Stat(logFile); err != nil {
+		t.Errorf("log file was not created: %v", err)
+	}
+}
+
+func TestRunWithWatchRestartsOnChange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("watch test relies on sh")
+	}
+
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	outFile := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(envFile, []byte("FOO=one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &ApplyCommand{}
+	reload := func() (map[string]string, error) {
+		return c.applyEnvFile(envFile, interpolateNone)
+	}
+	values, err := reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commandArgs := []string{"sh", "-c", `echo "$FOO" >> "$0"; exec sleep 30`, outFile}
+	printer := output.NewPrinter(output.FormatTable, true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- c.runWithWatch(ctx, values, reload, []string{envFile}, commandArgs, printer)
+	}()
+
+	waitForFile := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			data, _ := os.ReadFile(outFile)
+			if string(data) == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("output = %q, want %q", data, want)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	waitForFile("one\n")
+	if err := os.WriteFile(envFile, []byte("FOO=two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForFile("one\ntwo\n")
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("runWithWatch returned error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("runWithWatch did not stop after cancellation")
+	}
+}
+
+func TestExportAllEnvironmentsSummary(t *testing.T) {
+	dir := t.TempDir()
+	devFile := filepath.Join(dir, ".env.development")
+	stagingFile := filepath.Join(dir, ".env.staging")
+	for _, path := range []string{devFile, stagingFile} {
+		if err := os.WriteFile(path, []byte("PORT=3000\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exportDir := filepath.Join(dir, "out")
+	if err := os.MkdirAll(exportDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(exportDir, "staging.env"), []byte("OLD=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	context := &util.CommandContext{
+		IsInProject: true,
+		ProjectConfig: &parser.ProjectConfig{
+			Project: "app",
+			Environments: map[string]parser.EnvironmentDefinition{
+				"development": {Env: devFile},
+				"staging":     {Env: stagingFile},
+				"production":  {Env: filepath.Join(dir, ".env.production")},
+			},
+		},
+	}
+
+	var buf strings.Builder
+	printer := output.NewPrinterWithWriter(&buf, output.FormatJSON, true)
+	if err := (&ApplyCommand{}).exportAllEnvironments(context, exportDir, printer); err == nil {
+		t.Fatal("expected an error for the failing environment")
+	}
+
+	var summary struct {
+		Created int `json:"created"`
+		Updated int `json:"updated"`
+		Skipped int `json:"skipped"`
+		Failed  int `json:"failed"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &summary); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, buf.String())
+	}
+	if summary.Created != 1 || summary.Updated != 1 || summary.Skipped != 0 || summary.Failed != 1 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestPartitionSecrets(t *testing.T) {
+	values := map[string]string{
+		"PORT":         "3000",
+		"API_KEY":      "abc",
+		"DATABASE_URL": "postgres://user:pass@db/app",
+		"LOG_LEVEL":    "debug",
+	}
+	// DATABASE_URL is not caught by the name heuristic but is marked in the schema
+	secrets := map[string]bool{"DATABASE_URL": true}
+
+	only := partitionSecrets(values, secrets, true)
+	if len(only) != 2 || only["API_KEY"] != "abc" || only["DATABASE_URL"] == "" {
+		t.Errorf("only secrets = %v", only)
+	}
+
+	none := partitionSecrets(values, secrets, false)
+	if len(none) != 2 || none["PORT"] != "3000" || none["LOG_LEVEL"] != "debug" {
+		t.Errorf("no secrets = %v", none)
+	}
+}
+
+func TestRequireCleanBlocksMissingRequired(t *testing.T) {
+	context := &util.CommandContext{
+		IsInProject: true,
+		ProjectConfig: &parser.ProjectConfig{
+			Project: "app",
+			Schema: parser.ProjectConfigSchema{Variables: map[string]entities.Variable{
+				"DATABASE_URL": {Name: "DATABASE_URL", Type: "string", Required: true},
+				"PORT":         {Name: "PORT", Type: "integer"},
+			}},
+		},
+	}
+	printer := output.NewPrinter(output.FormatTable, true)
+	c := &ApplyCommand{}
+
+	err := c.requireClean(context, map[string]string{"PORT": "3000"}, printer)
+	if err == nil || !strings.Contains(err.Error(), "1 validation error") {
+		t.Fatalf("expected missing DATABASE_URL to block apply, got %v", err)
+	}
+
+	problems := validateValues(context.ProjectConfig.Schema.Variables, map[string]string{"PORT": "abc"})
+	if len(problems) != 2 || !strings.HasPrefix(problems[0], "DATABASE_URL:") || !strings.HasPrefix(problems[1], "PORT:") {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+
+	clean := map[string]string{"DATABASE_URL": "postgres://db/app", "PORT": "3000"}
+	if err := c.requireClean(context, clean, printer); err != nil {
+		t.Errorf("clean values should pass, got %v", err)
+	}
+}
+
+func TestValidateValuesSkipsEmptyOptionalValues(t *testing.T) {
+	variables := map[string]entities.Variable{
+		"DEBUG":   {Name: "DEBUG", Type: "boolean"},
+		"CODE":    {Name: "CODE", Type: "string", Regex: "^[A-Z]{3}$"},
+		"API_KEY": {Name: "API_KEY", Type: "string", Regex: "^sk-", Required: true},
+	}
+
+	problems := validateValues(variables, map[string]string{"DEBUG": "", "CODE": "", "API_KEY": ""})
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], "API_KEY:") {
+		t.Errorf("expected only the empty required API_KEY to be flagged, got %v", problems)
+	}
+}
+
+func TestCompleteEnvironmentNames(t *testing.T) {
+	chdirTemp(t)
+	projectConfig := `{
+  "project": "app",
+  "environments": {
+    "production": {"env": ".env.production"},
+    "development": {"env": ".env.development"},
+    "preview": {"env": ".env.preview"}
+  }
+}`
+	if err := os.WriteFile(".ee", []byte(projectConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewApplyCommand("global")
+
+	names, directive := completeEnvironmentNames(cmd, nil, "")
+	if strings.Join(names, ",") != "development,preview,production" {
+		t.Errorf("names = %v", names)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive = %v", directive)
+	}
+
+	names, _ = completeEnvironmentNames(cmd, nil, "pr")
+	if strings.Join(names, ",") != "preview,production" {
+		t.Errorf("prefix names = %v", names)
+	}
+
+	names, directive = completeEnvironmentNames(cmd, nil, "./")
+	if names != nil || directive != cobra.ShellCompDirectiveDefault {
+		t.Errorf("path argument should fall back to file completion, got %v %v", names, directive)
+	}
+}
+
+func TestRenameKeys(t *testing.T) {
+	values := map[string]string{"DATABASE_URL": "db", "PORT": "3000"}
+	sources := map[string]string{"DATABASE_URL": ".env", "PORT": ".env.local"}
+
+	prefixed, prefixedSources := renameKeys(values, sources, "", "APP_")
+	if prefixed["APP_DATABASE_URL"] != "db" || prefixed["APP_PORT"] != "3000" || len(prefixed) != 2 {
+		t.Errorf("prefixed = %v", prefixed)
+	}
+	if prefixedSources["APP_PORT"] != ".env.local" {
+		t.Errorf("sources not renamed: %v", prefixedSources)
+	}
+
+	imported := map[string]string{"APP_PORT": "8080", "PORT": "3000", "APP_": "x", "OTHER": "1"}
+	stripped, _ := renameKeys(imported, nil, "APP_", "")
+	want := map[string]string{"PORT": "8080", "APP_": "x", "OTHER": "1"}
+	if len(stripped) != len(want) {
+		t.Fatalf("stripped = %v, want %v", stripped, want)
+	}
+	for key, value := range want {
+		if stripped[key] != value {
+			t.Errorf("stripped[%s] = %q, want %q", key, stripped[key], value)
+		}
+	}
+}
+
+func TestApplyGetPrintsSingleValue(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.test", []byte("PORT=3000\nHOST=localhost\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := NewApplyCommand("global")
+		var out strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+		ctx := WithCommandContext(context.Background(), &util.CommandContext{})
+		err := cmd.ExecuteContext(ctx)
+		return out.String(), err
+	}
+
+	out, err := run("./.env.test", "--get", "PORT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "3000\n" {
+		t.Errorf("output = %q, want %q", out, "3000\n")
+	}
+
+	if _, err := run("./.env.test", "--get", "MISSING"); err == nil {
+		t.Error("expected an error for a variable that is not set")
+	}
+
+	// --get takes the schema name even when --prefix renames the output
+	out, err = run("./.env.test", "--get", "PORT", "--prefix", "APP_")
+	if err != nil || out != "3000\n" {
+		t.Errorf("with --prefix: output = %q, err = %v", out, err)
+	}
+
+	for _, extra := range [][]string{{"--output", "out.env"}, {"--detach"}, {"--watch"}} {
+		if _, err := run(append([]string{"./.env.test", "--get", "PORT"}, extra...)...); err == nil ||
+			!strings.Contains(err.Error(), "--get cannot be combined") {
+			t.Errorf("%v: expected a conflict error, got %v", extra, err)
+		}
+	}
+}
+
+func TestApplyStrictBlocksMissingRequired(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.development", []byte("PORT=3000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmdCtx := verifyTestContext(map[string]entities.Variable{
+		"DATABASE_URL": {Name: "DATABASE_URL", Type: "string", Required: true},
+		"API_KEY":      {Name: "API_KEY", Type: "string", Required: true},
+		"PORT":         {Name: "PORT", Type: "integer"},
+	})
+
+	run := func(args ...string) error {
+		cmd := NewApplyCommand("global")
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+		return cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx))
+	}
+
+	err := run("development", "--strict", "--dry-run", "-q")
+	if err == nil || !strings.Contains(err.Error(), "2 validation error") {
+		t.Fatalf("expected both missing variables to be reported, got %v", err)
+	}
+
+	if err := run("development", "--dry-run", "-q"); err != nil {
+		t.Errorf("without --strict apply should succeed, got %v", err)
+	}
+}
+
+func TestApplyLayersLocalOverrides(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.development", []byte("PORT=3000\nHOST=localhost\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".env.development.local", []byte("PORT=4000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmdCtx := verifyTestContext(map[string]entities.Variable{})
+	get := func(envOrFile, name string) string {
+		t.Helper()
+		cmd := NewApplyCommand("global")
+		var out strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{envOrFile, "--get", name})
+		if err := cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx)); err != nil {
+			t.Fatalf("apply %s --get %s: %v", envOrFile, name, err)
+		}
+		return strings.TrimSpace(out.String())
+	}
+
+	if got := get("development", "PORT"); got != "3000" {
+		t.Errorf("without local_overrides PORT = %q, want 3000", got)
+	}
+
+	cmdCtx.ProjectConfig.LocalOverrides = true
+	for _, envOrFile := range []string{"development", "./.env.development"} {
+		if got := get(envOrFile, "PORT"); got != "4000" {
+			t.Errorf("%s: PORT = %q, want the local override 4000", envOrFile, got)
+		}
+		if got := get(envOrFile, "HOST"); got != "localhost" {
+			t.Errorf("%s: HOST = %q, want localhost from the base file", envOrFile, got)
+		}
+	}
+}
+
+func TestMaskProjectSecretsUsesPrefixedNames(t *testing.T) {
+	context := verifyTestContext(map[string]entities.Variable{
+		"API_KEY": {Name: "API_KEY", Type: "string", Secret: true},
+		"PORT":    {Name: "PORT", Type: "number"},
+	})
+
+	var buf strings.Builder
+	printer := output.NewPrinterWithWriter(&buf, output.FormatJSON, true)
+	if err := maskProjectSecrets(printer, context, "APP_"); err != nil {
+		t.Fatal(err)
+	}
+	if err := printer.PrintValues(map[string]string{"APP_API_KEY": "secret", "APP_PORT": "3000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var printed map[string]string
+	if err := json.Unmarshal([]byte(buf.String()), &printed); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if printed["APP_API_KEY"] != output.SecretMask || printed["APP_PORT"] != "3000" {
+		t.Errorf("unexpected output: %v", printed)
+	}
+}
+
+func TestApplyInterpolateEnvIsSeparateToggle(t *testing.T) {
+	chdirTemp(t)
+	t.Setenv("EE_TEST_REGION", "eu-west-1")
+	if err := os.WriteFile(".env.test", []byte("BUCKET=assets-${EE_TEST_REGION}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := NewApplyCommand("global")
+		var out strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"./.env.test", "--get", "BUCKET"}, args...))
+		err := cmd.ExecuteContext(WithCommandContext(context.Background(), &util.CommandContext{}))
+		return strings.TrimSpace(out.String()), err
+	}
+
+	if _, err := run("--interpolate"); err == nil {
+		t.Error("--interpolate alone should not read the shell environment")
+	}
+	got, err := run("--interpolate-env")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "assets-eu-west-1" {
+		t.Errorf("BUCKET = %q, want assets-eu-west-1", got)
+	}
+}
+
+func TestApplyDryRunWarnsWhenSecretsCannotLoad(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.test", []byte("PORT=3000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmdCtx := &util.CommandContext{
+		IsInProject: true,
+		ProjectConfig: &parser.ProjectConfig{
+			Project: "app",
+			Schema:  parser.ProjectConfigSchema{Ref: "./missing-schema.yaml"},
+		},
+	}
+
+	for _, format := range []string{"dotenv", "json"} {
+		cmd := NewApplyCommand("global")
+		var stderr strings.Builder
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(&stderr)
+		cmd.SetArgs([]string{"./.env.test", "--dry-run", "-q", "-f", format})
+		if err := cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx)); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		warned := strings.Contains(stderr.String(), "values are not masked")
+		if warned != (format == "json") {
+			t.Errorf("%s: stderr = %q", format, stderr.String())
+		}
+	}
+}
+
+func TestApplyInterpolatesProjectEnvironment(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.development", []byte("HOST=localhost\nURL=http://${HOST}:3000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmdCtx := &util.CommandContext{
+		IsInProject: true,
+		ProjectConfig: &parser.ProjectConfig{
+			Project: "app",
+			Environments: map[string]parser.EnvironmentDefinition{
+				"development": {Env: ".env.development"},
+			},
+		},
+	}
+
+	run := func(args ...string) string {
+		cmd := NewApplyCommand("global")
+		var out strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"development", "--get", "URL"}, args...))
+		if err := cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx)); err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		return strings.TrimSpace(out.String())
+	}
+
+	if got := run(); got != "http://${HOST}:3000" {
+		t.Errorf("without --interpolate URL = %q", got)
+	}
+	if got := run("--interpolate"); got != "http://localhost:3000" {
+		t.Errorf("with --interpolate URL = %q", got)
+	}
+}
+
+func TestApplyFillsExpandedSchemaDefaults(t *testing.T) {
+	chdirTemp(t)
+	t.Setenv("EE_TEST_PORT", "8080")
+	if err := os.WriteFile(".env.development", []byte("HOST=localhost\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newContext := func(expand bool) *util.CommandContext {
+		return &util.CommandContext{
+			IsInProject: true,
+			ProjectConfig: &parser.ProjectConfig{
+				Project: "app",
+				Schema: parser.ProjectConfigSchema{
+					ExpandDefaults: expand,
+					Variables: map[string]entities.Variable{
+						"HOST": {Type: "string", Default: "0.0.0.0"},
+						"PORT": {Type: "number", Default: "${EE_TEST_PORT:-3000}"},
+					},
+				},
+				Environments: map[string]parser.EnvironmentDefinition{
+					"development": {Env: ".env.development"},
+				},
+			},
+		}
+	}
+
+	values, sources, err := (&ApplyCommand{}).applyProjectEnvironment(newContext(true), "development", interpolateNone)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["HOST"] != "localhost" || values["PORT"] != "8080" {
+		t.Errorf("unexpected values: %v", values)
+	}
+	if sources["PORT"] != util.DefaultSource {
+		t.Errorf("PORT source = %q, want %q", sources["PORT"], util.DefaultSource)
+	}
+
+	values, _, err = (&ApplyCommand{}).applyProjectEnvironment(newContext(false), "development", interpolateNone)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := values["PORT"]; ok {
+		t.Errorf("defaults must not be filled without expand_defaults: %v", values)
+	}
+}
+
+func TestApplyIgnoresUnresolvableSchemaRef(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.development", []byte("HOST=localhost\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmdCtx := &util.CommandContext{
+		IsInProject: true,
+		ProjectConfig: &parser.ProjectConfig{
+			Project: "app",
+			Schema:  parser.ProjectConfigSchema{Ref: "./missing-schema.yaml"},
+			Environments: map[string]parser.EnvironmentDefinition{
+				"development": {Env: ".env.development"},
+			},
+		},
+	}
+
+	cmd := NewApplyCommand("global")
+	var out strings.Builder
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"development", "--get", "HOST"})
+	if err := cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "localhost" {
+		t.Errorf("HOST = %q, want %q", got, "localhost")
+	}
+}
+
+func TestApplyRequireCleanValidatesBeforeSecretFilter(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.development", []byte("PORT=3000\nAPI_KEY=s3cret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmdCtx := verifyTestContext(map[string]entities.Variable{
+		"API_KEY": {Name: "API_KEY", Type: "string", Required: true, Secret: true},
+		"PORT":    {Name: "PORT", Type: "integer", Required: true},
+	})
+
+	run := func(args ...string) (string, error) {
+		cmd := NewApplyCommand("global")
+		var out strings.Builder
+		cmd.SetOut(&out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+		err := cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx))
+		return out.String(), err
+	}
+
+	// Each filter drops a required variable from the output, which must not
+	// count as missing
+	for filter, key := range map[string]string{"--no-secrets": "PORT", "--only-secrets": "API_KEY"} {
+		out, err := run("development", filter, "--require-clean", "--get", key)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", filter, err)
+		}
+		if out == "" {
+			t.Errorf("%s: expected %s in the output", filter, key)
+		}
+	}
+
+	if _, err := run("development", "--no-secrets", "--get", "API_KEY"); err == nil {
+		t.Error("--no-secrets should still drop API_KEY")
+	}
+}
+
+func TestApplyEvalSafeRejectsCommandSubstitution(t *testing.T) {
+	chdirTemp(t)
+	content := "PORT=3000\nAPI_KEY=$(rm -rf ~)\nTOKEN=`id`\n"
+	if err := os.WriteFile(".env.test", []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) error {
+		cmd := NewApplyCommand("global")
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"./.env.test", "-q"}, args...))
+		return cmd.ExecuteContext(WithCommandContext(context.Background(), &util.CommandContext{}))
+	}
+
+	for _, args := range [][]string{{"--dry-run"}, {"--output", "out.env"}} {
+		err := run(append(args, "--eval-safe")...)
+		if err == nil || !strings.Contains(err.Error(), "API_KEY, TOKEN") {
+			t.Errorf("%v: expected API_KEY and TOKEN to be rejected, got %v", args, err)
+		}
+	}
+	if _, err := os.Stat("out.env"); !os.IsNotExist(err) {
+		t.Errorf("rejected output must not be written, stat err = %v", err)
+	}
+
+	if err := run("--dry-run", "--eval-safe", "-f", "json"); err == nil {
+		t.Error("expected --eval-safe to be rejected with the json format")
+	}
+
+	if err := checkEvalSafe(map[string]string{"PORT": "3000", "NAME": "it's \"ok\""}); err != nil {
+		t.Errorf("safe values rejected: %v", err)
+	}
+}
+
+func TestApplyCommentBannerPrecedesVariables(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.test", []byte("PORT=3000\nHOST=localhost\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewApplyCommand("global")
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"./.env.test", "-q", "--output", "out.env", "--comment-banner"})
+	if err := cmd.ExecuteContext(WithCommandContext(context.Background(), &util.CommandContext{})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile("out.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("expected 4 banner lines and 2 variables, got %q", data)
+	}
+	for _, line := range lines[:4] {
+		if !strings.HasPrefix(line, "# ") {
+			t.Errorf("banner line is not a comment: %q", line)
+		}
+	}
+	if !strings.Contains(lines[0], "do not edit") || lines[2] != "# Sources: ./.env.test" {
+		t.Errorf("unexpected banner: %q", lines[:4])
+	}
+	if lines[4] != `HOST="localhost"` || lines[5] != `PORT="3000"` {
+		t.Errorf("variables must follow the banner: %q", lines[4:])
+	}
+
+	// The banner is still a comment block when parsed back as a .env file
+	values, err := (&ApplyCommand{}).applyEnvFile("out.env", interpolateNone)
+	if err != nil || len(values) != 2 {
+		t.Errorf("banner broke .env parsing: %v, %v", values, err)
+	}
+}
+
+func TestApplyFailOnExtraListsUndefinedVariables(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.development", []byte("PORT=3000\nDATABSE_URL=x\nDEBUG=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmdCtx := verifyTestContext(map[string]entities.Variable{
+		"DATABASE_URL": {Name: "DATABASE_URL", Type: "string"},
+		"PORT":         {Name: "PORT", Type: "integer"},
+	})
+
+	run := func(args ...string) error {
+		cmd := NewApplyCommand("global")
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"development", "--get", "PORT"}, args...))
+		return cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx))
+	}
+
+	err := run("--fail-on-extra")
+	if err == nil || !strings.Contains(err.Error(), "2 variable(s) not defined in the schema: DATABSE_URL, DEBUG") {
+		t.Fatalf("expected the extra variables to be listed, got %v", err)
+	}
+
+	if err := run(); err != nil {
+		t.Errorf("without --fail-on-extra apply should succeed, got %v", err)
+	}
+}
+
+func TestApplyNullAsEmpty(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.test", []byte("PORT=3000\nSENTRY_DSN=\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(args ...string) string {
+		cmd := NewApplyCommand("global")
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"./.env.test", "-q", "--output", "out.env"}, args...))
+		if err := cmd.ExecuteContext(WithCommandContext(context.Background(), &util.CommandContext{})); err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		data, err := os.ReadFile("out.env")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if got, want := write(), "PORT=\"3000\"\nSENTRY_DSN=\"\"\n"; got != want {
+		t.Errorf("default: got %q, want %q", got, want)
+	}
+	if got, want := write("--null-as-empty=false"), "PORT=\"3000\"\n"; got != want {
+		t.Errorf("--null-as-empty=false: got %q, want %q", got, want)
+	}
+}
+
+func TestApplySortBySchemaListsRequiredFirst(t *testing.T) {
+	chdirTemp(t)
+	content := "DEBUG=true\nPORT=3000\nAPI_KEY=k\nEXTRA=1\nDATABASE_URL=postgres://db\n"
+	if err := os.WriteFile(".env.development", []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmdCtx := verifyTestContext(map[string]entities.Variable{
+		"DATABASE_URL": {Name: "DATABASE_URL", Type: "string", Required: true},
+		"API_KEY":      {Name: "API_KEY", Type: "string", Required: true},
+		"DEBUG":        {Name: "DEBUG", Type: "boolean"},
+		"PORT":         {Name: "PORT", Type: "integer"},
+	})
+
+	cmd := NewApplyCommand("global")
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"development", "-q", "--output", "out.env", "--sort-by-schema"})
+	if err := cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile("out.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		keys = append(keys, strings.SplitN(line, "=", 2)[0])
+	}
+	// Required (schema order), optional (schema order), then keys outside the schema
+	want := "API_KEY DATABASE_URL DEBUG PORT EXTRA"
+	if got := strings.Join(keys, " "); got != want {
+		t.Errorf("order = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,232 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/n1rna/ee-cli/internal/output"
+	"github.com/n1rna/ee-cli/internal/util"
+)
+
+// watchDebounce is how long to wait after the last file event before
+// restarting, so editors that write a file in several steps trigger one reload
+const watchDebounce = 250 * time.Millisecond
+
+// watchStopTimeout is how long a child gets to exit after an interrupt
+// before it is killed
+const watchStopTimeout = 5 * time.Second
+
+// watchedFiles returns the .env files that make up the applied values
+func (c *ApplyCommand) watchedFiles(
+	context *util.CommandContext,
+	envOrFile string,
+	envFiles []string,
+) ([]string, error) {
+	var files []string
+
+	if isFilePath(envOrFile) {
+		files = append(files, envOrFile)
+	} else {
+		envDef, err := context.GetEnvironment(envOrFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get environment definition: %w", err)
+		}
+		if envDef.Env != "" {
+			files = append(files, envDef.Env)
+		}
+		files = append(files, envDef.Sheets...)
+		for _, source := range envDef.Sources {
+			if path, ok := source.(string); ok {
+				files = append(files, path)
+			}
+		}
+	}
+
+	// Watch local overrides too, so creating one triggers a reload
+	if localOverrides(context) {
+		for _, file := range files {
+			files = append(files, file+util.LocalOverrideSuffix)
+		}
+	}
+
+	files = append(files, envFiles...)
+	return files, nil
+}
+
+// watchedProcess is a running child and a channel closed once it has exited
+type watchedProcess struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// runWithWatch runs the command with values and restarts it with freshly
+// resolved values whenever one of files changes, until ctx is cancelled
+func (c *ApplyCommand) runWithWatch(
+	ctx context.Context,
+	values map[string]string,
+	reload func() (map[string]string, error),
+	files []string,
+	commandArgs []string,
+	printer *output.Printer,
+) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+
+	// Watch parent directories rather than the files themselves: editors
+	// often save by replacing the file, which drops a direct watch
+	watched := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", file, err)
+		}
+		watched[abs] = true
+		dir := filepath.Dir(abs)
+		if dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+		dirs[dir] = true
+	}
+
+	printer.Info(fmt.Sprintf(
+		"Watching %d file(s) for changes: %s", len(files), strings.Join(files, ", "),
+	))
+
+	proc, err := c.startWatchedProcess(values, commandArgs, printer)
+	if err != nil {
+		return err
+	}
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+
+	for {
+		var exited chan struct{}
+		if proc != nil {
+			exited = proc.done
+		}
+
+		select {
+		case <-ctx.Done():
+			c.stopWatchedProcess(proc)
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				c.stopWatchedProcess(proc)
+				return nil
+			}
+			abs, err := filepath.Abs(event.Name)
+			if err != nil || !watched[abs] {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			debounce.Reset(watchDebounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				c.stopWatchedProcess(proc)
+				return nil
+			}
+			printer.Warning(fmt.Sprintf("File watcher error: %v", err))
+
+		case <-debounce.C:
+			newValues, err := reload()
+			if err != nil {
+				printer.Warning(fmt.Sprintf(
+					"Failed to reload environment, keeping the current process: %v", err,
+				))
+				continue
+			}
+			printer.Info("Change detected, restarting command")
+			c.stopWatchedProcess(proc)
+			proc, err = c.startWatchedProcess(newValues, commandArgs, printer)
+			if err != nil {
+				printer.Warning(err.Error())
+			}
+
+		case <-exited:
+			if state := proc.cmd.ProcessState; state != nil && !state.Success() {
+				printer.Warning(fmt.Sprintf("Command exited: %s", state))
+			}
+			printer.Info("Command exited, waiting for changes")
+			proc = nil
+		}
+	}
+}
+
+// startWatchedProcess starts the command with the given values without
+// waiting for it to exit
+func (c *ApplyCommand) startWatchedProcess(
+	values map[string]string,
+	commandArgs []string,
+	printer *output.Printer,
+) (*watchedProcess, error) {
+	cmd := exec.Command(commandArgs[0], commandArgs[1:]...)
+	cmd.Env = buildEnviron(values)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	printer.Info(fmt.Sprintf(
+		"Running command: %s", strings.Join(commandArgs, " "),
+	))
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	proc := &watchedProcess{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		_ = cmd.Wait()
+		close(proc.done)
+	}()
+	return proc, nil
+}
+
+// stopWatchedProcess interrupts the child and waits for it to exit, killing
+// it if it does not stop within watchStopTimeout
+func (c *ApplyCommand) stopWatchedProcess(proc *watchedProcess) {
+	if proc == nil {
+		return
+	}
+
+	select {
+	case <-proc.done:
+		return
+	default:
+	}
+
+	// Interrupt is not supported for child processes on Windows
+	if runtime.GOOS == "windows" {
+		_ = proc.cmd.Process.Kill()
+	} else {
+		_ = proc.cmd.Process.Signal(os.Interrupt)
+	}
+
+	select {
+	case <-proc.done:
+	case <-time.After(watchStopTimeout):
+		_ = proc.cmd.Process.Kill()
+		<-proc.done
+	}
+}
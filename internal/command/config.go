@@ -0,0 +1,225 @@
+// Package command implements the ee config command for viewing and persisting user settings.
+package command
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/n1rna/ee-cli/internal/config"
+	"github.com/n1rna/ee-cli/internal/output"
+)
+
+// keyBaseDir is the read-only key reporting the effective base directory
+const keyBaseDir = "base_dir"
+
+// ConfigCommand handles the ee config command
+type ConfigCommand struct{}
+
+// NewConfigCommand creates a new ee config command
+func NewConfigCommand(groupId string) *cobra.Command {
+	cc := &ConfigCommand{}
+
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "View and set ee configuration",
+		Long: `View and set ee configuration.
+
+Settings are stored in config.json inside the base directory (~/.ee, or
+$EE_HOME). base_dir itself is read-only and can only be changed with EE_HOME.
+
+Keys:
+  editor        Editor used by 'ee config edit' (default: $VISUAL, then $EDITOR)
+  output.format Default --format for commands that support it ($EE_FORMAT
+                takes precedence; an explicit --format always wins)
+
+Examples:
+  ee config list
+  ee config get editor
+  ee config set editor "code --wait"
+  ee config set output.format ""   # clear a setting
+  ee config edit                   # open config.json in the editor`,
+		GroupID: groupId,
+	}
+
+	getCmd := &cobra.Command{
+		Use:               "get <key>",
+		Short:             "Print the effective value of a setting",
+		Args:              cobra.ExactArgs(1),
+		RunE:              cc.RunGet,
+		ValidArgsFunction: completeConfigKeys,
+	}
+
+	setCmd := &cobra.Command{
+		Use:               "set <key> <value>",
+		Short:             "Persist a setting (an empty value clears it)",
+		Args:              cobra.ExactArgs(2),
+		RunE:              cc.RunSet,
+		ValidArgsFunction: completeConfigKeys,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the effective configuration",
+		Args:  cobra.NoArgs,
+		RunE:  cc.RunList,
+	}
+	listCmd.Flags().StringP("format", "f", "text", "Output format: text, json")
+	annotateFormats(listCmd, "text", "json")
+
+	editCmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Open the settings file in the configured editor",
+		Long: `Open config.json in the configured editor (the editor setting, then $VISUAL,
+then $EDITOR), creating it if needed. The file is checked after the editor exits.`,
+		Args: cobra.NoArgs,
+		RunE: cc.RunEdit,
+	}
+
+	cmd.AddCommand(getCmd, setCmd, listCmd, editCmd)
+
+	return cmd
+}
+
+// RunGet executes ee config get
+func (c *ConfigCommand) RunGet(cmd *cobra.Command, args []string) error {
+	cmdCtx, err := RequireCommandContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	values := effectiveConfig(cmdCtx.Config)
+	value, ok := values[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown config key '%s' (valid keys: %s)", args[0], configKeysList())
+	}
+
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), value)
+	return err
+}
+
+// RunSet executes ee config set
+func (c *ConfigCommand) RunSet(cmd *cobra.Command, args []string) error {
+	cmdCtx, err := RequireCommandContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	key, value := args[0], args[1]
+	if key == keyBaseDir {
+		return fmt.Errorf("%s is read-only; set the EE_HOME environment variable instead", keyBaseDir)
+	}
+
+	cfg := cmdCtx.Config
+	if err := cfg.Settings.Set(key, value); err != nil {
+		return err
+	}
+	if err := config.SaveSettings(cfg.SettingsPath(), cfg.Settings); err != nil {
+		return err
+	}
+
+	printer := output.NewPrinter(output.FormatTable, false)
+	if value == "" {
+		printer.Success(fmt.Sprintf("Cleared %s", key))
+	} else {
+		printer.Success(fmt.Sprintf("Set %s = %s", key, value))
+	}
+	return nil
+}
+
+// RunList executes ee config list
+func (c *ConfigCommand) RunList(cmd *cobra.Command, args []string) error {
+	cmdCtx, err := RequireCommandContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	values := effectiveConfig(cmdCtx.Config)
+	out := cmd.OutOrStdout()
+
+	switch format {
+	case "json":
+		return encodeJSON(out, values)
+	case "text":
+		for _, key := range sortedKeys(values) {
+			if _, err := fmt.Fprintf(out, "%s = %s\n", key, values[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format '%s' (supported: text, json)", format)
+	}
+}
+
+// RunEdit executes ee config edit
+func (c *ConfigCommand) RunEdit(cmd *cobra.Command, args []string) error {
+	cmdCtx, err := RequireCommandContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	cfg := cmdCtx.Config
+	editor := strings.Fields(cfg.Settings.EditorCommand())
+	if len(editor) == 0 {
+		return fmt.Errorf("no editor configured: run 'ee config set editor <command>' or set $EDITOR")
+	}
+
+	path := cfg.SettingsPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := config.SaveSettings(path, cfg.Settings); err != nil {
+			return err
+		}
+	}
+
+	editCmd := exec.Command(editor[0], append(editor[1:], path)...)
+	editCmd.Stdin = cmd.InOrStdin()
+	editCmd.Stdout = cmd.OutOrStdout()
+	editCmd.Stderr = cmd.ErrOrStderr()
+	if err := editCmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+
+	if _, err := config.LoadSettings(path); err != nil {
+		return fmt.Errorf("settings file is invalid, run 'ee config edit' again to fix it: %w", err)
+	}
+	return nil
+}
+
+// effectiveConfig returns every config key with the value ee will use,
+// including fallbacks that are not stored in the settings file
+func effectiveConfig(cfg *config.Config) map[string]string {
+	return map[string]string{
+		keyBaseDir:             cfg.BaseDir,
+		config.KeyEditor:       cfg.Settings.EditorCommand(),
+		config.KeyOutputFormat: cfg.Settings.DefaultFormat(),
+	}
+}
+
+// configKeysList returns all config keys, sorted, for error messages
+func configKeysList() string {
+	keys := append([]string{keyBaseDir}, config.SettingKeys...)
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// completeConfigKeys completes the key argument of ee config get/set
+func completeConfigKeys(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	keys := config.SettingKeys
+	if cmd.Name() == "get" {
+		keys = append([]string{keyBaseDir}, keys...)
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
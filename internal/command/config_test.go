@@ -0,0 +1,177 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/n1rna/ee-cli/internal/config"
+	"github.com/n1rna/ee-cli/internal/util"
+)
+
+func TestConfigSetGetList(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "vi")
+	t.Setenv("EE_FORMAT", "")
+	baseDir := t.TempDir()
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		// Reload settings each run, as a fresh process would
+		settings, err := config.LoadSettings(filepath.Join(baseDir, config.SettingsFileName))
+		if err != nil {
+			t.Fatalf("failed to load settings: %v", err)
+		}
+		cmdCtx := &util.CommandContext{Config: &config.Config{BaseDir: baseDir, Settings: settings}}
+
+		cmd := NewConfigCommand("")
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err = cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx))
+		return out.String(), err
+	}
+
+	if got, err := run("get", "editor"); err != nil || got != "vi\n" {
+		t.Errorf("get editor before set = %q, %v; want $EDITOR fallback", got, err)
+	}
+
+	if _, err := run("set", "editor", "code --wait"); err != nil {
+		t.Fatalf("set editor: %v", err)
+	}
+	if _, err := run("set", "output.format", "json"); err != nil {
+		t.Fatalf("set output.format: %v", err)
+	}
+
+	if got, _ := run("get", "editor"); got != "code --wait\n" {
+		t.Errorf("get editor = %q, want persisted value", got)
+	}
+
+	list, err := run("list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, want := range []string{
+		"output.format = json",
+		"base_dir = " + baseDir,
+		"editor = code --wait",
+	} {
+		if !strings.Contains(list, want) {
+			t.Errorf("list missing %q:\n%s", want, list)
+		}
+	}
+
+	if _, err := run("set", "output.format", "xml"); err == nil {
+		t.Error("expected an error for an unsupported output.format")
+	}
+	if _, err := run("set", "base_dir", "/tmp"); err == nil {
+		t.Error("expected base_dir to be read-only")
+	}
+	if _, err := run("get", "nope"); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+}
+
+func TestLoadConfigToleratesBrokenSettings(t *testing.T) {
+	t.Run("malformed file", func(t *testing.T) {
+		baseDir := t.TempDir()
+		t.Setenv("EE_HOME", baseDir)
+		if err := os.WriteFile(filepath.Join(baseDir, config.SettingsFileName), []byte("{not json"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			t.Fatalf("a broken settings file must not fail LoadConfig: %v", err)
+		}
+		if cfg.SettingsLoadError == nil {
+			t.Error("expected SettingsLoadError to be recorded")
+		}
+	})
+
+	t.Run("base dir is a file", func(t *testing.T) {
+		chdirTemp(t)
+		// The ".ee" fallback base dir is the project file itself
+		if err := os.WriteFile(".ee", []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("EE_HOME", ".ee")
+
+		cfg, err := config.LoadConfig()
+		if err != nil || cfg.SettingsLoadError != nil {
+			t.Errorf("expected empty settings, got %v / %v", err, cfg.SettingsLoadError)
+		}
+	})
+}
+
+func TestConfigEditRunsEditor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the editor")
+	}
+
+	baseDir := t.TempDir()
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	script := "#!/bin/sh\nprintf '{\"output\": {\"format\": \"yaml\"}}' > \"$1\"\n"
+	if err := os.WriteFile(editor, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmdCtx := &util.CommandContext{Config: &config.Config{
+		BaseDir:  baseDir,
+		Settings: config.Settings{Editor: editor},
+	}}
+	cmd := NewConfigCommand("")
+	cmd.SetArgs([]string{"edit"})
+	if err := cmd.ExecuteContext(WithCommandContext(context.Background(), cmdCtx)); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+
+	settings, err := config.LoadSettings(filepath.Join(baseDir, config.SettingsFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Output.Format != "yaml" {
+		t.Errorf("output.format = %q, want the value written by the editor", settings.Output.Format)
+	}
+}
+
+func TestApplyDefaultFormat(t *testing.T) {
+	t.Run("unset flag takes the default", func(t *testing.T) {
+		cmd := NewVersionCommand("", BuildInfo{})
+		if err := ApplyDefaultFormat(cmd, "json"); err != nil {
+			t.Fatal(err)
+		}
+		flag := cmd.Flags().Lookup("format")
+		if flag.Value.String() != "json" || flag.Changed {
+			t.Errorf("format = %q (changed %v), want json and unchanged", flag.Value, flag.Changed)
+		}
+	})
+
+	t.Run("explicit flag wins", func(t *testing.T) {
+		cmd := NewHydrateCommand("")
+		if err := cmd.Flags().Set("format", "yaml"); err != nil {
+			t.Fatal(err)
+		}
+		if err := ApplyDefaultFormat(cmd, "json"); err != nil {
+			t.Fatal(err)
+		}
+		if got := cmd.Flags().Lookup("format").Value.String(); got != "yaml" {
+			t.Errorf("format = %q, want explicit yaml", got)
+		}
+	})
+
+	t.Run("unsupported default is ignored", func(t *testing.T) {
+		cmd := NewVersionCommand("", BuildInfo{})
+		if err := ApplyDefaultFormat(cmd, "csv"); err != nil {
+			t.Fatal(err)
+		}
+		if got := cmd.Flags().Lookup("format").Value.String(); got != "text" {
+			t.Errorf("format = %q, want text", got)
+		}
+	})
+}
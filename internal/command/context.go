@@ -3,7 +3,13 @@ package command
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/spf13/cobra"
+
+	"github.com/n1rna/ee-cli/internal/config"
+	"github.com/n1rna/ee-cli/internal/parser"
 	"github.com/n1rna/ee-cli/internal/util"
 )
 
@@ -44,3 +50,50 @@ func RequireProjectContext(ctx context.Context) (*util.CommandContext, error) {
 
 	return cmdCtx, nil
 }
+
+// completeEnvironmentNames completes the first argument with the environment
+// names from the project's .ee file. Arguments that look like paths, and
+// directories without a project, fall back to file completion.
+func completeEnvironmentNames(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if strings.HasPrefix(toComplete, ".") ||
+		strings.HasPrefix(toComplete, "/") ||
+		strings.HasPrefix(toComplete, "~") {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	var projectConfig *parser.ProjectConfig
+	if ctx := cmd.Context(); ctx != nil {
+		if cmdCtx := GetCommandContext(ctx); cmdCtx != nil && cmdCtx.IsInProject {
+			projectConfig = cmdCtx.ProjectConfig
+		}
+	}
+	if projectConfig == nil {
+		// Completion may run without the root pre-run hook, so read .ee directly
+		path, _ := cmd.Flags().GetString("config")
+		if path == "" {
+			path = config.ProjectConfigFileName
+		}
+		loaded, err := parser.LoadProjectConfigFromPath(path)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		projectConfig = loaded
+	}
+
+	var names []string
+	for _, name := range projectConfig.GetEnvironmentNames() {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,35 @@
+package command
+
+import (
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// formatsAnnotation lists the values a command's --format flag accepts. Only
+// commands carrying it pick up the global default format, so the default
+// never selects a format a command cannot render.
+const formatsAnnotation = "ee_formats"
+
+// annotateFormats records the values accepted by cmd's --format flag
+func annotateFormats(cmd *cobra.Command, formats ...string) {
+	_ = cmd.Flags().SetAnnotation("format", formatsAnnotation, formats)
+}
+
+// ApplyDefaultFormat makes format the value of cmd's --format flag unless the
+// flag was given explicitly or the command does not support that format. The
+// flag is not marked as changed, so commands still treat it as a default.
+func ApplyDefaultFormat(cmd *cobra.Command, format string) error {
+	if format == "" {
+		return nil
+	}
+
+	flag := cmd.Flags().Lookup("format")
+	if flag == nil || flag.Changed {
+		return nil
+	}
+	if !slices.Contains(flag.Annotations[formatsAnnotation], format) {
+		return nil
+	}
+	return flag.Value.Set(format)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/n1rna/ee-cli/internal/config"
 	"github.com/n1rna/ee-cli/internal/entities"
+	"github.com/n1rna/ee-cli/internal/fsutil"
 	"github.com/n1rna/ee-cli/internal/output"
 	"github.com/n1rna/ee-cli/internal/util"
 )
@@ -48,13 +49,15 @@ Examples:
 
   # Output as YAML to a file
   ee hydrate dev -f yaml -o config.yaml`,
-		Args:    cobra.ExactArgs(1),
-		RunE:    hc.Run,
-		GroupID: groupId,
+		Args:              cobra.ExactArgs(1),
+		RunE:              hc.Run,
+		ValidArgsFunction: completeEnvironmentNames,
+		GroupID:           groupId,
 	}
 
 	cmd.Flags().StringP("output", "o", "", "Write output to file instead of stdout")
 	cmd.Flags().StringP("format", "f", "dotenv", "Output format: dotenv, json, yaml")
+	annotateFormats(cmd, "dotenv", "json", "yaml")
 
 	return cmd
 }
@@ -83,7 +86,7 @@ func (c *HydrateCommand) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load schema variables
-	schemaVariables, err := c.loadSchema(context)
+	schemaVariables, err := loadSchemaVariables(context)
 	if err != nil {
 		return fmt.Errorf("failed to load schema: %w", err)
 	}
@@ -130,7 +133,7 @@ func (c *HydrateCommand) Run(cmd *cobra.Command, args []string) error {
 
 	// Write to file or stdout
 	if outputFile != "" {
-		if err := os.WriteFile(outputFile, []byte(rendered), 0o644); err != nil {
+		if err := fsutil.WriteFileAtomic(outputFile, []byte(rendered), 0o644); err != nil {
 			return fmt.Errorf("failed to write output file: %w", err)
 		}
 		printer.Success(fmt.Sprintf("Wrote %d variables to %s", len(values), outputFile))
@@ -141,34 +144,6 @@ func (c *HydrateCommand) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// loadSchema loads the project schema variables (inline or referenced)
-func (c *HydrateCommand) loadSchema(
-	context *util.CommandContext,
-) (map[string]entities.Variable, error) {
-	schema := context.ProjectConfig.Schema
-
-	// Inline schema
-	if schema.Variables != nil {
-		return schema.Variables, nil
-	}
-
-	// Schema file reference
-	if schema.Ref != "" {
-		loaded, err := entities.ResolveSchemaRef(schema.Ref)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load schema '%s': %w", schema.Ref, err)
-		}
-
-		variables := make(map[string]entities.Variable, len(loaded.Variables))
-		for _, v := range loaded.Variables {
-			variables[v.Name] = v
-		}
-		return variables, nil
-	}
-
-	return nil, fmt.Errorf("no schema defined in project config")
-}
-
 // hydrateValues resolves each schema variable with priority: source files > shell env > schema defaults
 func (c *HydrateCommand) hydrateValues(
 	schemaVariables map[string]entities.Variable,
@@ -53,6 +53,8 @@ Examples:
 	cmd.Flags().
 		StringSlice("var", []string{}, "Add schema variable (format:name:type:title:required:default)")
 	cmd.Flags().BoolP("force", "f", false, "Overwrite existing .ee file")
+	cmd.Flags().Bool("allow-invalid-names", false,
+		"Accept --var names that are not valid shell variable names (for legacy configs)")
 	cmd.Flags().BoolP("quiet", "q", false, "Suppress non-error output")
 
 	return cmd
@@ -68,6 +70,7 @@ func (c *InitCommand) Run(cmd *cobra.Command, args []string) error {
 	schemaRef, _ := cmd.Flags().GetString("schema")
 	variables, _ := cmd.Flags().GetStringSlice("var")
 	force, _ := cmd.Flags().GetBool("force")
+	allowInvalidNames, _ := cmd.Flags().GetBool("allow-invalid-names")
 
 	// Determine project name
 	var projectName string
@@ -93,7 +96,7 @@ func (c *InitCommand) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Build schema configuration
-	schema, err := c.buildSchemaConfig(schemaRef, variables)
+	schema, err := c.buildSchemaConfig(schemaRef, variables, allowInvalidNames)
 	if err != nil {
 		return fmt.Errorf("failed to build schema config: %w", err)
 	}
@@ -143,6 +146,7 @@ func (c *InitCommand) Run(cmd *cobra.Command, args []string) error {
 func (c *InitCommand) buildSchemaConfig(
 	schemaRef string,
 	variables []string,
+	allowInvalidNames bool,
 ) (parser.ProjectConfigSchema, error) {
 	schema := parser.ProjectConfigSchema{}
 
@@ -154,9 +158,13 @@ func (c *InitCommand) buildSchemaConfig(
 
 	// If variables are provided, create inline schema
 	if len(variables) > 0 {
+		validator := entities.Validator{AllowInvalidNames: allowInvalidNames}
 		schema.Variables = make(map[string]entities.Variable)
 		for _, varDef := range variables {
 			variable, err := c.parseVariableDefinition(varDef)
+			if err == nil {
+				err = validator.ValidateVariable(&variable)
+			}
 			if err != nil {
 				return schema, fmt.Errorf("invalid variable definition '%s': %w", varDef, err)
 			}
@@ -195,7 +203,8 @@ func (c *InitCommand) buildSchemaConfig(
 
 // parseVariableDefinition parses a variable definition string (name:type:title:required:default)
 func (c *InitCommand) parseVariableDefinition(varDef string) (entities.Variable, error) {
-	parts := strings.Split(varDef, ":")
+	// The default is the last field and may itself contain colons (URLs)
+	parts := strings.SplitN(varDef, ":", 5)
 	if len(parts) < 2 {
 		return entities.Variable{}, fmt.Errorf("format should be name:type:title:required:default")
 	}
@@ -0,0 +1,38 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSchemaConfigValidatesVariables(t *testing.T) {
+	c := &InitCommand{}
+
+	schema, err := c.buildSchemaConfig("", []string{
+		"PORT:integer:Port:false:3000",
+		"API_URL:url:API:true:https://api.example.com",
+	}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schema.Variables["PORT"].Default != "3000" {
+		t.Errorf("unexpected variables: %v", schema.Variables)
+	}
+
+	for _, varDef := range []string{
+		"PORT:integer:Port:false:3000.5",
+		"PORT:number:Port:false:abc",
+		"API_URL:url:API:false:not a url",
+		"PORT:integr:Port:false:3000",
+		"my-var:string",
+	} {
+		_, err := c.buildSchemaConfig("", []string{varDef}, false)
+		if err == nil || !strings.Contains(err.Error(), "invalid variable definition") {
+			t.Errorf("%s: expected a validation error, got %v", varDef, err)
+		}
+	}
+
+	if _, err := c.buildSchemaConfig("", []string{"my-var:string"}, true); err != nil {
+		t.Errorf("--allow-invalid-names should accept my-var, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/n1rna/ee-cli/internal/entities"
+	"github.com/n1rna/ee-cli/internal/logger"
+	"github.com/n1rna/ee-cli/internal/output"
+	"github.com/n1rna/ee-cli/internal/util"
+)
+
+// errNoSchema is returned when the .ee file neither inlines nor references a schema
+var errNoSchema = errors.New("no schema defined in project config")
+
+// loadProjectSchema returns the full project schema, building one from the
+// inline variables (sorted by name) when the .ee file does not reference a
+// schema file
+func loadProjectSchema(context *util.CommandContext) (*entities.Schema, error) {
+	projectSchema := context.ProjectConfig.Schema
+
+	if projectSchema.Variables != nil {
+		schema := &entities.Schema{
+			Name:           context.ProjectConfig.Project,
+			Title:          projectSchema.Title,
+			Description:    projectSchema.Description,
+			ExpandDefaults: projectSchema.ExpandDefaults,
+		}
+
+		names := make([]string, 0, len(projectSchema.Variables))
+		for name := range projectSchema.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			variable := projectSchema.Variables[name]
+			if variable.Name == "" {
+				variable.Name = name
+			}
+			schema.Variables = append(schema.Variables, variable)
+		}
+		return schema, nil
+	}
+
+	if projectSchema.Ref != "" {
+		schema, err := entities.ResolveSchemaRef(projectSchema.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load schema '%s': %w", projectSchema.Ref, err)
+		}
+		return schema, nil
+	}
+
+	return nil, errNoSchema
+}
+
+// schemaVariableMap indexes schema variables by name
+func schemaVariableMap(schema *entities.Schema) map[string]entities.Variable {
+	variables := make(map[string]entities.Variable, len(schema.Variables))
+	for _, variable := range schema.Variables {
+		variables[variable.Name] = variable
+	}
+	return variables
+}
+
+// loadSchemaVariables returns the project schema variables keyed by name,
+// with ${NAME:-fallback} defaults expanded when the schema opts in
+func loadSchemaVariables(context *util.CommandContext) (map[string]entities.Variable, error) {
+	schema, err := loadProjectSchema(context)
+	if err != nil {
+		return nil, err
+	}
+	return expandSchemaDefaults(schemaVariableMap(schema), schema.ExpandDefaults), nil
+}
+
+// expandSchemaDefaults resolves ${NAME:-fallback} references in defaults
+// against the host environment when the schema opts in via expand_defaults
+func expandSchemaDefaults(
+	variables map[string]entities.Variable,
+	expand bool,
+) map[string]entities.Variable {
+	if !expand {
+		return variables
+	}
+
+	expanded := make(map[string]entities.Variable, len(variables))
+	for name, variable := range variables {
+		variable.Default = entities.ExpandDefault(variable.Default, os.LookupEnv)
+		expanded[name] = variable
+	}
+	return expanded
+}
+
+// expandedDefaults returns the schema defaults the resolver fills in for
+// missing keys. Only schemas that opt in via expand_defaults have any. Inline
+// schemas are only read when they opt in, and a referenced schema that cannot
+// be loaded contributes no defaults, so apply keeps working without it.
+func expandedDefaults(context *util.CommandContext) map[string]string {
+	projectSchema := context.ProjectConfig.Schema
+	if projectSchema.Variables != nil && !projectSchema.ExpandDefaults {
+		return nil
+	}
+
+	schema, err := loadProjectSchema(context)
+	if err != nil {
+		if !errors.Is(err, errNoSchema) {
+			logger.Debugf("skipping schema defaults: %v", err)
+		}
+		return nil
+	}
+	if !schema.ExpandDefaults {
+		return nil
+	}
+
+	defaults := make(map[string]string)
+	for _, variable := range schema.Variables {
+		if variable.Default != "" {
+			defaults[variable.Name] = variable.Default
+		}
+	}
+	return defaults
+}
+
+// maskProjectSecrets marks the project's secret variables, renamed with
+// prefix, for masking in printer's value output
+func maskProjectSecrets(printer *output.Printer, context *util.CommandContext, prefix string) error {
+	secrets, err := projectSecrets(context)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, prefix+name)
+	}
+	printer.SetSecrets(names)
+	return nil
+}
+
+// projectSecrets returns the names of variables marked secret in the project
+// schema. Outside a project, or without a schema, there are none.
+func projectSecrets(context *util.CommandContext) (map[string]bool, error) {
+	secrets := make(map[string]bool)
+	if context == nil || !context.IsInProject {
+		return secrets, nil
+	}
+
+	schema, err := loadProjectSchema(context)
+	if errors.Is(err, errNoSchema) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, variable := range schema.Variables {
+		if variable.Secret {
+			secrets[variable.Name] = true
+		}
+	}
+	return secrets, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -48,6 +49,9 @@ Examples:
 	cmd.Flags().Bool("dry-run", false, "Show what would be pushed without executing")
 	cmd.Flags().Bool("quiet", false, "Suppress non-error output")
 	cmd.Flags().String("mode", "", "Override push mode (bundled or individual)")
+	cmd.Flags().StringP("format", "f", "table",
+		"Output format for the result summary (table, json); json keeps progress on stderr")
+	annotateFormats(cmd, "table", "json")
 
 	return cmd
 }
@@ -56,8 +60,12 @@ func runPush(cmd *cobra.Command, args []string) error {
 	quiet, _ := cmd.Flags().GetBool("quiet")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	modeOverride, _ := cmd.Flags().GetString("mode")
+	format, _ := cmd.Flags().GetString("format")
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unsupported format '%s' (supported: table, json)", format)
+	}
 
-	printer := output.NewPrinter(output.FormatTable, quiet)
+	printer := output.NewPrinter(output.Format(format), quiet)
 
 	// Require project context
 	ctx, err := RequireProjectContext(cmd.Context())
@@ -130,8 +138,13 @@ func runPush(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to resolve environment %q: %w", envName, err)
 	}
 
+	// JSON callers always get a summary document on stdout, even when
+	// nothing is pushed
 	if len(values) == 0 {
 		printer.Warning(fmt.Sprintf("No values found for environment %q", envName))
+		if format == "json" {
+			return printer.PrintSummary(output.OperationSummary{})
+		}
 		return nil
 	}
 
@@ -140,6 +153,10 @@ func runPush(cmd *cobra.Command, args []string) error {
 		printer.Info(fmt.Sprintf("Dry run: would push %d secrets to %s (%s, mode: %s)",
 			len(values), originName, originCfg.Type, mode))
 		printSecretsSummary(printer, values, mode, originCfg)
+		// Nothing is written, so every secret counts as skipped
+		if format == "json" {
+			return printer.PrintSummary(output.OperationSummary{Skipped: len(values)})
+		}
 		return nil
 	}
 
@@ -147,23 +164,31 @@ func runPush(cmd *cobra.Command, args []string) error {
 		len(values), originName, originCfg.Type, mode))
 
 	// Push
+	start := time.Now()
 	result, err := driver.Push(envName, values, mode, false)
 	if err != nil {
 		return fmt.Errorf("push failed: %w", err)
 	}
 
-	// Report results
-	if len(result.Errors) > 0 {
-		for _, e := range result.Errors {
-			printer.Error(fmt.Sprintf("  %v", e))
+	// Report results; the summary below is the footer under them
+	for _, e := range result.Errors {
+		printer.Error(fmt.Sprintf("  %v", e))
+	}
+	if format == "table" {
+		if len(result.Errors) > 0 {
+			printer.Warning(fmt.Sprintf("Pushed %d secrets to %s with %d errors",
+				result.SecretsCount, originName, len(result.Errors)))
+		} else {
+			printer.Success(fmt.Sprintf("Pushed %d secrets to %s", result.SecretsCount, originName))
 		}
-		printer.Warning(fmt.Sprintf("Pushed %d secrets with %d errors",
-			result.SecretsCount, len(result.Errors)))
-	} else {
-		printer.Success(fmt.Sprintf("Pushed %d secrets to %s", result.SecretsCount, originName))
 	}
 
-	return nil
+	// Origins overwrite secrets in place, so every pushed secret counts as updated
+	return printer.PrintSummary(output.OperationSummary{
+		Updated:  result.SecretsCount,
+		Failed:   len(result.Errors),
+		Duration: time.Since(start),
+	})
 }
 
 // resolveArgs resolves origin name and environment name from positional arguments.
@@ -38,8 +38,11 @@ When run without subcommands, displays all environment variables in the current
 	cmd.Flags().StringP("filter", "I", "",
 		"Filter environment variables using wildcard patterns separated by comma, pipe, or slash "+
 			"(e.g., 'PATH*,USER*', '*_URL|*_KEY', '!CLAUDE*/NODE*')")
-	cmd.Flags().StringP("format", "f", "env", "Output format (env, json, dotenv)")
+	cmd.Flags().StringP("format", "f", "env", "Output format (env, json, yaml, csv, dotenv)")
+	annotateFormats(cmd, "env", "json", "yaml", "csv", "dotenv")
 	cmd.Flags().BoolP("mask", "m", false, "Mask sensitive environment variable values")
+	cmd.Flags().Bool("reveal", false,
+		"Show values of variables the project schema marks secret in json, yaml and csv output")
 
 	return cmd
 }
@@ -50,6 +53,7 @@ func (c *RootCommand) Run(cmd *cobra.Command, args []string) error {
 	filter, _ := cmd.Flags().GetString("filter")
 	format, _ := cmd.Flags().GetString("format")
 	mask, _ := cmd.Flags().GetBool("mask")
+	reveal, _ := cmd.Flags().GetBool("reveal")
 
 	// Get all environment variables
 	envVars := os.Environ()
@@ -89,12 +93,18 @@ func (c *RootCommand) Run(cmd *cobra.Command, args []string) error {
 	switch format {
 	case "env":
 		return c.printEnvFormat(envMap)
-	case "json":
+	case "json", "yaml", "csv":
+		if !reveal {
+			if err := maskProjectSecrets(printer, GetCommandContext(cmd.Context()), ""); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(),
+					"Warning: could not load secret variables, values are not masked: %v\n", err)
+			}
+		}
 		return printer.PrintValues(envMap)
 	case "dotenv":
 		return printer.PrintDotEnv(envMap)
 	default:
-		return fmt.Errorf("unsupported format: %s (supported: env, json, dotenv)", format)
+		return fmt.Errorf("unsupported format: %s (supported: env, json, yaml, csv, dotenv)", format)
 	}
 }
 
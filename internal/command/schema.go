@@ -0,0 +1,315 @@
+// Package command implements the ee schema command for converting the project
+// schema to and from other formats such as JSON Schema.
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/n1rna/ee-cli/internal/config"
+	"github.com/n1rna/ee-cli/internal/entities"
+	"github.com/n1rna/ee-cli/internal/fsutil"
+	"github.com/n1rna/ee-cli/internal/output"
+	"github.com/n1rna/ee-cli/internal/parser"
+)
+
+// SchemaCommand handles the ee schema command
+type SchemaCommand struct{}
+
+// NewSchemaCommand creates a new ee schema command
+func NewSchemaCommand(groupId string) *cobra.Command {
+	sc := &SchemaCommand{}
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Convert the project schema to and from other formats",
+		Long: `Convert the project schema to and from other formats.
+
+Examples:
+  # Export the project schema as a draft-07 JSON Schema
+  ee schema export --format jsonschema -o config.schema.json
+
+  # Convert an existing JSON Schema into an ee schema file
+  ee schema import config.schema.json -o schema.yaml
+
+  # Check the project schema and a hand-edited schema file
+  ee schema validate
+  ee schema validate ./schemas/api.yaml`,
+		GroupID: groupId,
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the project schema",
+		Long: `Export the project schema (inline or referenced from .ee).
+
+Formats:
+  jsonschema  - draft-07 JSON Schema: variables become properties, regex
+                becomes pattern, url becomes a string with format uri
+  yaml, json  - the ee schema file format`,
+		Args: cobra.NoArgs,
+		RunE: sc.RunExport,
+	}
+	exportCmd.Flags().StringP("format", "f", "jsonschema", "Output format: jsonschema, yaml, json")
+	exportCmd.Flags().StringP("output", "o", "", "Write output to file instead of stdout")
+
+	importCmd := &cobra.Command{
+		Use:   "import <jsonschema-file>",
+		Short: "Convert a JSON Schema into an ee schema file",
+		Long: `Convert a JSON Schema document into an ee schema file.
+
+Each entry of "properties" becomes a variable: type string with format uri
+becomes url, pattern becomes regex, minimum/maximum become min/max, writeOnly
+becomes secret, and names in "required" are required. Keywords with no ee
+equivalent are skipped with a warning.
+
+Reference the result from .ee with { "schema": { "ref": "./schema.yaml" } }.`,
+		Args: cobra.ExactArgs(1),
+		RunE: sc.RunImport,
+	}
+	importCmd.Flags().String("name", "", "Schema name (default: input file name)")
+	importCmd.Flags().StringP("format", "f", "yaml", "Output format: yaml, json")
+	importCmd.Flags().StringP("output", "o", "", "Write output to file instead of stdout")
+	importCmd.Flags().Bool("allow-invalid-names", false,
+		"Accept property names that are not valid shell variable names (for legacy configs)")
+
+	validateCmd := &cobra.Command{
+		Use:   "validate [schema-file...]",
+		Short: "Check schemas for invalid definitions",
+		Long: `Check schema definitions for problems: unknown types, invalid regex patterns,
+defaults that violate their own constraints, min greater than max, invalid
+variable names, and variables defined more than once.
+
+With no arguments the project schema (inline or referenced from .ee) is checked;
+otherwise each schema file given is checked. Every issue is reported, not just
+the first, and the command exits non-zero if any schema has issues.`,
+		RunE: sc.RunValidate,
+	}
+	validateCmd.Flags().StringP("format", "f", "text", "Output format: text, json")
+	validateCmd.Flags().Bool("allow-invalid-names", false,
+		"Accept variable names that are not valid shell variable names (for legacy configs)")
+	annotateFormats(validateCmd, "text", "json")
+
+	cmd.AddCommand(exportCmd, importCmd, validateCmd)
+
+	return cmd
+}
+
+// RunExport executes the ee schema export command
+func (c *SchemaCommand) RunExport(cmd *cobra.Command, args []string) error {
+	context, err := RequireProjectContext(cmd.Context())
+	if err != nil {
+		return fmt.Errorf(
+			"schema export requires a project context (%s file): %w",
+			config.ProjectConfigFileName,
+			err,
+		)
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	outputFile, _ := cmd.Flags().GetString("output")
+
+	schema, err := loadProjectSchema(context)
+	if err != nil {
+		return err
+	}
+
+	write := func(w io.Writer) error {
+		return c.writeSchema(w, schema, format)
+	}
+
+	if outputFile == "" {
+		return write(cmd.OutOrStdout())
+	}
+
+	if err := fsutil.WriteFileAtomicFunc(outputFile, 0o644, write); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	output.NewPrinter(output.FormatTable, false).Success(fmt.Sprintf(
+		"Wrote schema with %d variables to %s", len(schema.Variables), outputFile,
+	))
+	return nil
+}
+
+// RunImport executes the ee schema import command
+func (c *SchemaCommand) RunImport(cmd *cobra.Command, args []string) error {
+	inputFile := args[0]
+	name, _ := cmd.Flags().GetString("name")
+	format, _ := cmd.Flags().GetString("format")
+	outputFile, _ := cmd.Flags().GetString("output")
+	allowInvalidNames, _ := cmd.Flags().GetBool("allow-invalid-names")
+
+	if format != "yaml" && format != "yml" && format != "json" {
+		return fmt.Errorf("unsupported format '%s' (supported: yaml, json)", format)
+	}
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", inputFile, err)
+	}
+
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
+		name = strings.TrimSuffix(name, ".schema")
+	}
+
+	schema, warnings, err := parser.ImportJSONSchema(data, name)
+	if err != nil {
+		return err
+	}
+	validator := &entities.Validator{AllowInvalidNames: allowInvalidNames}
+	if err := validator.ValidateSchema(schema); err != nil {
+		return fmt.Errorf("imported schema is invalid: %w", err)
+	}
+
+	// Warnings go to stderr so the converted schema can be piped from stdout
+	for _, warning := range warnings {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", warning)
+	}
+
+	write := func(w io.Writer) error {
+		return c.writeSchema(w, schema, format)
+	}
+
+	if outputFile == "" {
+		return write(cmd.OutOrStdout())
+	}
+
+	if err := fsutil.WriteFileAtomicFunc(outputFile, 0o644, write); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	output.NewPrinter(output.FormatTable, false).Success(fmt.Sprintf(
+		"Imported %d variables into %s", len(schema.Variables), outputFile,
+	))
+	return nil
+}
+
+// schemaValidation is the result of validating one schema
+type schemaValidation struct {
+	Schema string                 `json:"schema"`
+	Source string                 `json:"source"`
+	Valid  bool                   `json:"valid"`
+	Issues []entities.SchemaIssue `json:"issues"`
+}
+
+// RunValidate executes the ee schema validate command
+func (c *SchemaCommand) RunValidate(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format '%s' (supported: text, json)", format)
+	}
+
+	allowInvalidNames, _ := cmd.Flags().GetBool("allow-invalid-names")
+
+	var results []schemaValidation
+	validator := &entities.Validator{AllowInvalidNames: allowInvalidNames}
+	check := func(schema *entities.Schema, source string) {
+		issues := validator.SchemaIssues(schema)
+		if issues == nil {
+			issues = []entities.SchemaIssue{}
+		}
+		results = append(results, schemaValidation{
+			Schema: schema.Name,
+			Source: source,
+			Valid:  len(issues) == 0,
+			Issues: issues,
+		})
+	}
+
+	if len(args) == 0 {
+		context, err := RequireProjectContext(cmd.Context())
+		if err != nil {
+			return fmt.Errorf(
+				"schema validate without arguments requires a project context (%s file): %w",
+				config.ProjectConfigFileName,
+				err,
+			)
+		}
+		schema, err := loadProjectSchema(context)
+		if err != nil {
+			return err
+		}
+		source := context.ProjectConfig.Schema.Ref
+		if source == "" {
+			source = config.ProjectConfigFileName
+		}
+		check(schema, source)
+	}
+
+	for _, path := range args {
+		schema, err := entities.LoadSchemaFromFile(path)
+		if err != nil {
+			return err
+		}
+		check(schema, path)
+	}
+
+	out := cmd.OutOrStdout()
+	if format == "json" {
+		if err := encodeJSON(out, results); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			if result.Valid {
+				_, _ = fmt.Fprintf(out, "✓ %s (%s)\n", result.Schema, result.Source)
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "✗ %s (%s): %d issue(s)\n", result.Schema, result.Source, len(result.Issues))
+			for _, issue := range result.Issues {
+				if issue.Variable == "" {
+					_, _ = fmt.Fprintf(out, "    %s\n", issue.Message)
+				} else {
+					_, _ = fmt.Fprintf(out, "    %s: %s\n", issue.Variable, issue.Message)
+				}
+			}
+		}
+	}
+
+	invalid := 0
+	for _, result := range results {
+		if !result.Valid {
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d schema(s) have issues", invalid, len(results))
+	}
+	return nil
+}
+
+// writeSchema encodes schema to w in the given format
+func (c *SchemaCommand) writeSchema(w io.Writer, schema *entities.Schema, format string) error {
+	switch format {
+	case "jsonschema":
+		return encodeJSON(w, parser.ExportJSONSchema(schema))
+	case "json":
+		return encodeJSON(w, schema)
+	case "yaml", "yml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(schema); err != nil {
+			return fmt.Errorf("failed to encode schema: %w", err)
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unsupported format '%s' (supported: jsonschema, yaml, json)", format)
+	}
+}
+
+// encodeJSON writes v to w as indented JSON
+func encodeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
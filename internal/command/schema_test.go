@@ -0,0 +1,154 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/n1rna/ee-cli/internal/entities"
+	"github.com/n1rna/ee-cli/internal/parser"
+	"github.com/n1rna/ee-cli/internal/util"
+)
+
+func TestSchemaValidateReportsIssuesPerVariable(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.yaml")
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(good, []byte(`name: good
+variables:
+  - name: PORT
+    type: number
+    default: "3000"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte(`name: bad
+variables:
+  - name: PORT
+    type: number
+    default: abc
+  - name: CODE
+    type: string
+    regex: "[a-"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := NewSchemaCommand("")
+		cmd.SilenceUsage = true
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"validate"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if out, err := run(good); err != nil || !strings.Contains(out, "✓ good") {
+		t.Errorf("valid schema: output %q, err %v", out, err)
+	}
+
+	out, err := run("-f", "json", good, bad)
+	if err == nil {
+		t.Fatal("expected a non-nil error when a schema has issues")
+	}
+
+	var results []schemaValidation
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+	if len(results) != 2 || !results[0].Valid || results[1].Valid {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if len(results[1].Issues) != 2 ||
+		results[1].Issues[0].Variable != "PORT" || results[1].Issues[1].Variable != "CODE" {
+		t.Errorf("unexpected issues: %+v", results[1].Issues)
+	}
+}
+
+func TestLoadSchemaVariablesInlineAndRef(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile("schema.yaml", []byte(`name: app
+variables:
+  - name: API_KEY
+    type: string
+    secret: true
+  - name: PORT
+    type: number
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	inline := verifyTestContext(map[string]entities.Variable{
+		"API_KEY": {Type: "string", Secret: true},
+		"PORT":    {Name: "PORT", Type: "number"},
+	})
+	ref := verifyTestContext(nil)
+	ref.ProjectConfig.Schema = parser.ProjectConfigSchema{Ref: "./schema.yaml"}
+
+	for name, context := range map[string]*util.CommandContext{"inline": inline, "ref": ref} {
+		variables, err := loadSchemaVariables(context)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(variables) != 2 || variables["API_KEY"].Name != "API_KEY" || variables["PORT"].Type != "number" {
+			t.Errorf("%s: unexpected variables %+v", name, variables)
+		}
+
+		secrets, err := projectSecrets(context)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !secrets["API_KEY"] || secrets["PORT"] {
+			t.Errorf("%s: unexpected secrets %v", name, secrets)
+		}
+	}
+
+	none := verifyTestContext(nil)
+	if _, err := loadSchemaVariables(none); !errors.Is(err, errNoSchema) {
+		t.Errorf("expected errNoSchema without a schema, got %v", err)
+	}
+}
+
+func TestSchemaAllowInvalidNames(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "legacy.yaml")
+	if err := os.WriteFile(legacy, []byte(`name: legacy
+variables:
+  - name: app.port
+    type: number
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	jsonSchema := filepath.Join(dir, "legacy.schema.json")
+	if err := os.WriteFile(jsonSchema, []byte(`{"properties": {"app.port": {"type": "number"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) error {
+		cmd := NewSchemaCommand("")
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	for _, args := range [][]string{
+		{"validate", legacy},
+		{"import", jsonSchema},
+	} {
+		if err := run(args...); err == nil {
+			t.Errorf("%v: expected legacy name to be rejected", args)
+		}
+		if err := run(append(args, "--allow-invalid-names")...); err != nil {
+			t.Errorf("%v --allow-invalid-names: unexpected error %v", args, err)
+		}
+	}
+}
@@ -2,14 +2,18 @@
 package command
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/n1rna/ee-cli/internal/config"
 	"github.com/n1rna/ee-cli/internal/entities"
+	"github.com/n1rna/ee-cli/internal/fsutil"
 	"github.com/n1rna/ee-cli/internal/output"
 	"github.com/n1rna/ee-cli/internal/parser"
 	"github.com/n1rna/ee-cli/internal/util"
@@ -23,6 +27,8 @@ type VerificationResult struct {
 	ProjectValid      bool
 	SchemaValid       bool
 	EnvironmentsValid bool
+	SchemaTitle       string
+	SchemaDescription string
 	Issues            []VerificationIssue
 	Warnings          []string
 }
@@ -32,11 +38,22 @@ type VerificationIssue struct {
 	Type        string // "missing_env_file", "missing_variable", "extra_variable", "type_mismatch"
 	Environment string
 	Variable    string
+	Secret      bool
+	File        string
 	Expected    string
 	Actual      string
 	Description string
 }
 
+// displayValue returns value for reports, masked when the issue concerns a
+// secret variable and reveal is not set
+func (issue VerificationIssue) displayValue(value string, reveal bool) string {
+	if issue.Secret && !reveal {
+		return output.SecretMask
+	}
+	return value
+}
+
 // NewVerifyCommand creates a new ee verify command
 func NewVerifyCommand(groupId string) *cobra.Command {
 	vc := &VerifyCommand{}
@@ -73,6 +90,7 @@ Examples:
 	cmd.Flags().Bool("verbose", false, "Show detailed verification output")
 	cmd.Flags().String("env", "", "Verify specific environment only")
 	cmd.Flags().Bool("quiet", false, "Suppress non-error output")
+	cmd.Flags().Bool("reveal", false, "Show values of secret variables in reports")
 
 	return cmd
 }
@@ -96,6 +114,7 @@ func (c *VerifyCommand) Run(cmd *cobra.Command, args []string) error {
 
 	// Get flags
 	fix, _ := cmd.Flags().GetBool("fix")
+	reveal, _ := cmd.Flags().GetBool("reveal")
 	envFilter, _ := cmd.Flags().GetString("env")
 
 	if verbose {
@@ -110,12 +129,12 @@ func (c *VerifyCommand) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Report results
-	c.reportResults(result, printer, verbose)
+	c.reportResults(result, printer, verbose, reveal)
 
 	// Apply fixes if requested
 	if fix && len(result.Issues) > 0 {
 		printer.Info("\nApplying fixes...")
-		c.applyFixes(context, result, printer)
+		c.applyFixes(context, result, printer, reveal)
 		printer.Success("Fixes applied successfully")
 	}
 
@@ -154,6 +173,9 @@ func (c *VerifyCommand) verifyProject(
 
 	if verbose && len(schemaVariables) > 0 {
 		printer.Info(fmt.Sprintf("Schema loaded with %d variables", len(schemaVariables)))
+		if label := schemaLabel(result.SchemaTitle, result.SchemaDescription); label != "" {
+			printer.Info(fmt.Sprintf("Schema: %s", label))
+		}
 	}
 
 	// 2. Verify environments
@@ -171,47 +193,53 @@ func (c *VerifyCommand) verifyProject(
 		if verbose {
 			printer.Info(fmt.Sprintf("Verifying environment: %s", envName))
 		}
-		c.verifyEnvironment(envName, envDef, schemaVariables, result)
+		c.verifyEnvironment(envName, envDef, schemaVariables, context.ProjectConfig.LocalOverrides, result)
 	}
 
 	return result, nil
 }
 
-// loadProjectSchema loads and validates the project schema
+// loadProjectSchema loads the project schema, recording load failures and
+// title/description on the result
 func (c *VerifyCommand) loadProjectSchema(
 	context *util.CommandContext,
 	result *VerificationResult,
 ) (map[string]entities.Variable, error) {
-	schema := context.ProjectConfig.Schema
-
-	// Handle inline schema
-	if schema.Variables != nil {
-		return schema.Variables, nil
+	schema, err := loadProjectSchema(context)
+	if errors.Is(err, errNoSchema) {
+		result.Warnings = append(result.Warnings, "No schema defined for project")
+		return make(map[string]entities.Variable), nil
+	}
+	if err != nil {
+		result.SchemaValid = false
+		result.Issues = append(result.Issues, VerificationIssue{
+			Type:        "schema_error",
+			Description: err.Error(),
+		})
+		return nil, err
 	}
 
-	// Handle schema file reference
-	if schema.Ref != "" {
-		loadedSchema, err := entities.ResolveSchemaRef(schema.Ref)
-		if err != nil {
-			result.SchemaValid = false
-			result.Issues = append(result.Issues, VerificationIssue{
-				Type:        "schema_error",
-				Description: fmt.Sprintf("Failed to load schema '%s': %v", schema.Ref, err),
-			})
-			return nil, fmt.Errorf("failed to load schema: %w", err)
-		}
+	result.SchemaTitle = schema.Title
+	result.SchemaDescription = schema.Description
+	return schemaVariableMap(schema), nil
+}
 
-		// Convert to map
-		variables := make(map[string]entities.Variable)
-		for _, variable := range loadedSchema.Variables {
-			variables[variable.Name] = variable
-		}
-		return variables, nil
+// schemaLabel combines a schema title and description for display, truncating
+// long descriptions to keep the line readable
+func schemaLabel(title, description string) string {
+	const maxDescription = 60
+	if len(description) > maxDescription {
+		description = description[:maxDescription-3] + "..."
 	}
 
-	// No schema defined
-	result.Warnings = append(result.Warnings, "No schema defined for project")
-	return make(map[string]entities.Variable), nil
+	switch {
+	case title != "" && description != "":
+		return fmt.Sprintf("%s - %s", title, description)
+	case title != "":
+		return title
+	default:
+		return description
+	}
 }
 
 // verifyEnvironment verifies a single environment
@@ -219,6 +247,7 @@ func (c *VerifyCommand) verifyEnvironment(
 	envName string,
 	envDef parser.EnvironmentDefinition,
 	schemaVariables map[string]entities.Variable,
+	localOverrides bool,
 	result *VerificationResult,
 ) {
 	// Find .env files for this environment
@@ -236,11 +265,12 @@ func (c *VerifyCommand) verifyEnvironment(
 
 	// Verify each .env file
 	for _, envFile := range envFiles {
-		c.verifyEnvFile(envName, envFile, schemaVariables, result)
+		c.verifyEnvFile(envName, envFile, schemaVariables, localOverrides, result)
 	}
 }
 
-// findEnvFiles finds .env files referenced by an environment definition
+// findEnvFiles finds .env files referenced by an environment definition.
+// Local overrides are not listed; verifyEnvFile layers them onto their base file.
 func (c *VerifyCommand) findEnvFiles(
 	envName string, envDef parser.EnvironmentDefinition,
 ) []string {
@@ -270,10 +300,13 @@ func (c *VerifyCommand) findEnvFiles(
 	return envFiles
 }
 
-// verifyEnvFile verifies a single .env file against the schema
+// verifyEnvFile verifies a single .env file against the schema. With
+// localOverrides, values from <envFile>.local are layered on top first, so
+// variables satisfied by the override are not reported.
 func (c *VerifyCommand) verifyEnvFile(
 	envName, envFile string,
 	schemaVariables map[string]entities.Variable,
+	localOverrides bool,
 	result *VerificationResult,
 ) {
 	// Check if file exists
@@ -299,6 +332,28 @@ func (c *VerifyCommand) verifyEnvFile(
 		return
 	}
 
+	// Track the file each value came from so issues point at the right file
+	valueFiles := make(map[string]string, len(envVars))
+	for varName := range envVars {
+		valueFiles[varName] = envFile
+	}
+	if local, exists := util.LocalOverridePath(envFile); localOverrides && exists {
+		localVars, err := c.parseEnvFile(local)
+		if err != nil {
+			result.EnvironmentsValid = false
+			result.Issues = append(result.Issues, VerificationIssue{
+				Type:        "parse_error",
+				Environment: envName,
+				Description: fmt.Sprintf("Failed to parse '%s': %v", local, err),
+			})
+			return
+		}
+		for varName, value := range localVars {
+			envVars[varName] = value
+			valueFiles[varName] = local
+		}
+	}
+
 	// Check for missing required variables
 	for varName, schemaVar := range schemaVariables {
 		if _, exists := envVars[varName]; !exists {
@@ -321,12 +376,43 @@ func (c *VerifyCommand) verifyEnvFile(
 		}
 	}
 
+	// Check present values against the schema's type and regex constraints
+	validator := entities.NewValidator()
+	for varName, value := range envVars {
+		schemaVar, exists := schemaVariables[varName]
+		if !exists || value == "" {
+			continue
+		}
+		if err := validator.ValidateValue(&schemaVar, value); err != nil {
+			valueFile := valueFiles[varName]
+			// Validator errors quote the value, so keep them out of secret descriptions
+			description := fmt.Sprintf("Variable '%s' in %s is invalid: %v", varName, valueFile, err)
+			if schemaVar.Secret {
+				description = fmt.Sprintf(
+					"Secret variable '%s' in %s is not a valid %s",
+					varName, valueFile, schemaVar.Type,
+				)
+			}
+			result.EnvironmentsValid = false
+			result.Issues = append(result.Issues, VerificationIssue{
+				Type:        "type_mismatch",
+				Environment: envName,
+				Variable:    varName,
+				Secret:      schemaVar.Secret,
+				File:        valueFile,
+				Expected:    schemaVar.Type,
+				Actual:      value,
+				Description: description,
+			})
+		}
+	}
+
 	// Check for extra variables not in schema
 	for varName := range envVars {
 		if _, exists := schemaVariables[varName]; !exists && len(schemaVariables) > 0 {
 			result.Warnings = append(
 				result.Warnings,
-				fmt.Sprintf("Variable '%s' in %s not defined in schema", varName, envFile),
+				fmt.Sprintf("Variable '%s' in %s not defined in schema", varName, valueFiles[varName]),
 			)
 		}
 	}
@@ -339,8 +425,12 @@ func (c *VerifyCommand) parseEnvFile(filename string) (map[string]string, error)
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	if err := entities.CheckEncoding(content); err != nil {
+		return nil, err
+	}
+
 	result := make(map[string]string)
-	lines := strings.Split(string(content), "\n")
+	lines := strings.Split(string(entities.TrimBOM(content)), "\n")
 
 	for lineNum, line := range lines {
 		line = strings.TrimSpace(line)
@@ -382,6 +472,7 @@ func (c *VerifyCommand) reportResults(
 	result *VerificationResult,
 	printer *output.Printer,
 	verbose bool,
+	reveal bool,
 ) {
 	if len(result.Issues) == 0 && len(result.Warnings) == 0 {
 		if verbose {
@@ -405,6 +496,19 @@ func (c *VerifyCommand) reportResults(
 						issue.Environment,
 					),
 				)
+			case "type_mismatch":
+				printer.Error(
+					fmt.Sprintf(
+						"  ✗ Invalid value: %s in %s (expected %s, got %q)",
+						issue.Variable,
+						issue.Environment,
+						issue.Expected,
+						issue.displayValue(issue.Actual, reveal),
+					),
+				)
+				if verbose {
+					printer.Error(fmt.Sprintf("      %s", issue.Description))
+				}
 			case "schema_error":
 				printer.Error(fmt.Sprintf("  ✗ Schema error: %s", issue.Description))
 			case "parse_error":
@@ -422,6 +526,28 @@ func (c *VerifyCommand) reportResults(
 			printer.Warning(fmt.Sprintf("  ⚠ %s", warning))
 		}
 	}
+
+	printer.Info(summarizeIssues(result))
+}
+
+// summarizeIssues returns a one-line count of issues by kind
+func summarizeIssues(result *VerificationResult) string {
+	counts := make(map[string]int)
+	for _, issue := range result.Issues {
+		counts[issue.Type]++
+	}
+
+	other := len(result.Issues) -
+		counts["missing_env_file"] - counts["missing_variable"] - counts["type_mismatch"]
+
+	return fmt.Sprintf(
+		"Summary: %d missing file(s), %d missing variable(s), %d invalid value(s), %d other issue(s), %d warning(s)",
+		counts["missing_env_file"],
+		counts["missing_variable"],
+		counts["type_mismatch"],
+		other,
+		len(result.Warnings),
+	)
 }
 
 // applyFixes applies automatic fixes for detected issues
@@ -429,6 +555,7 @@ func (c *VerifyCommand) applyFixes(
 	context *util.CommandContext,
 	result *VerificationResult,
 	printer *output.Printer,
+	reveal bool,
 ) {
 	for _, issue := range result.Issues {
 		switch issue.Type {
@@ -447,6 +574,22 @@ func (c *VerifyCommand) applyFixes(
 			} else {
 				printer.Info(fmt.Sprintf("Added missing variable %s to %s", issue.Variable, issue.Environment))
 			}
+
+		case "type_mismatch":
+			fixed, err := c.fixTypeMismatch(context, issue)
+			switch {
+			case err != nil:
+				printer.Warning(fmt.Sprintf("Failed to fix variable %s: %v", issue.Variable, err))
+			case fixed == "":
+				printer.Warning(fmt.Sprintf(
+					"Cannot fix %s=%q in %s automatically; manual intervention needed",
+					issue.Variable, issue.displayValue(issue.Actual, reveal), issue.File,
+				))
+			default:
+				printer.Info(fmt.Sprintf("Rewrote %s=%q to %q in %s",
+					issue.Variable, issue.displayValue(issue.Actual, reveal),
+					issue.displayValue(fixed, reveal), issue.File))
+			}
 		}
 	}
 }
@@ -489,6 +632,9 @@ func (c *VerifyCommand) createMissingEnvFile(
 		if variable.Required {
 			content += "# required: true\n"
 		}
+		if variable.Secret {
+			content += "# secret: true\n"
+		}
 
 		value := variable.Default
 		if value == "" {
@@ -541,6 +687,9 @@ func (c *VerifyCommand) addMissingVariable(
 	if variable.Required {
 		content += "# required: true\n"
 	}
+	if variable.Secret {
+		content += "# secret: true\n"
+	}
 
 	value := variable.Default
 	if value == "" {
@@ -562,3 +711,112 @@ func (c *VerifyCommand) addMissingVariable(
 	_, err = file.WriteString(content)
 	return err
 }
+
+// fixTypeMismatch rewrites an invalid value in its .env file. Convertible
+// values are rewritten to their canonical form; otherwise the schema default
+// is used and the original line is kept as a comment. It returns the new value,
+// or "" when the value could not be fixed.
+func (c *VerifyCommand) fixTypeMismatch(
+	context *util.CommandContext,
+	issue VerificationIssue,
+) (string, error) {
+	schemaVariables, err := c.loadProjectSchema(context, &VerificationResult{})
+	if err != nil {
+		return "", err
+	}
+
+	variable, exists := schemaVariables[issue.Variable]
+	if !exists {
+		return "", fmt.Errorf("variable %s not found in schema", issue.Variable)
+	}
+
+	validator := entities.NewValidator()
+	if canonical, ok := canonicalValue(variable, issue.Actual); ok &&
+		validator.ValidateValue(&variable, canonical) == nil {
+		return canonical, rewriteEnvValue(issue.File, issue.Variable, canonical, "")
+	}
+
+	if variable.Default != "" && validator.ValidateValue(&variable, variable.Default) == nil {
+		comment := fmt.Sprintf(
+			"# ee verify --fix: replaced invalid value, was: %s=%s",
+			issue.Variable, issue.Actual,
+		)
+		if variable.Secret {
+			comment = "# ee verify --fix: replaced invalid secret value"
+		}
+		return variable.Default, rewriteEnvValue(issue.File, issue.Variable, variable.Default, comment)
+	}
+
+	return "", nil
+}
+
+// canonicalValue converts obviously-convertible values to the canonical form
+// for the variable's type: surrounding whitespace is trimmed, boolean words
+// such as "1", "yes" or "on" become "true"/"false", and whole floats such as
+// "3000.0" become integers. It reports false when nothing could be converted.
+func canonicalValue(variable entities.Variable, value string) (string, bool) {
+	trimmed := strings.TrimSpace(value)
+
+	switch variable.Type {
+	case "boolean":
+		switch strings.ToLower(trimmed) {
+		case "true", "1", "yes", "y", "on":
+			return "true", true
+		case "false", "0", "no", "n", "off":
+			return "false", true
+		}
+		return "", false
+	case "integer":
+		if _, err := strconv.ParseInt(trimmed, 10, 64); err != nil {
+			number, err := strconv.ParseFloat(trimmed, 64)
+			if err != nil || number != math.Trunc(number) || math.Abs(number) > 1<<53 {
+				return "", false
+			}
+			return strconv.FormatInt(int64(number), 10), true
+		}
+	}
+
+	if trimmed != value {
+		return trimmed, true
+	}
+	return "", false
+}
+
+// rewriteEnvValue replaces the value of key in a .env file, optionally
+// inserting a comment line above it
+func rewriteEnvValue(path, key, value, comment string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	found := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		parts := strings.SplitN(trimmed, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) != key {
+			continue
+		}
+
+		replacement := fmt.Sprintf("%s=%s", key, value)
+		if comment != "" {
+			replacement = comment + "\n" + replacement
+		}
+		lines[i] = replacement
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("variable %s not found in %s", key, path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm())
+}
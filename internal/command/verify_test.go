@@ -0,0 +1,280 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/n1rna/ee-cli/internal/entities"
+	"github.com/n1rna/ee-cli/internal/output"
+	"github.com/n1rna/ee-cli/internal/parser"
+	"github.com/n1rna/ee-cli/internal/util"
+)
+
+// chdirTemp switches into a fresh temporary directory for the duration of
+// the test, since verify resolves .env files relative to the working directory.
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(orig) })
+}
+
+func verifyTestContext(variables map[string]entities.Variable) *util.CommandContext {
+	return &util.CommandContext{
+		IsInProject: true,
+		ProjectConfig: &parser.ProjectConfig{
+			Project: "app",
+			Schema:  parser.ProjectConfigSchema{Variables: variables},
+			Environments: map[string]parser.EnvironmentDefinition{
+				"development": {Env: ".env.development"},
+			},
+		},
+	}
+}
+
+func TestVerifyFixesTypeMismatch(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.development", []byte("DEBUG=1\nPORT=abc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	context := verifyTestContext(map[string]entities.Variable{
+		"DEBUG": {Name: "DEBUG", Type: "boolean"},
+		"PORT":  {Name: "PORT", Type: "number"},
+	})
+	printer := output.NewPrinter(output.FormatTable, true)
+	c := &VerifyCommand{}
+
+	result, err := c.verifyProject(context, "", printer, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mismatches := map[string]bool{}
+	for _, issue := range result.Issues {
+		if issue.Type == "type_mismatch" {
+			mismatches[issue.Variable] = true
+		}
+	}
+	if !mismatches["DEBUG"] || !mismatches["PORT"] {
+		t.Fatalf("expected type_mismatch for DEBUG and PORT, got %+v", result.Issues)
+	}
+
+	c.applyFixes(context, result, printer, false)
+
+	data, err := os.ReadFile(".env.development")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "DEBUG=true") {
+		t.Errorf("expected DEBUG=1 to be rewritten to DEBUG=true, got:\n%s", content)
+	}
+	if !strings.Contains(content, "PORT=abc") {
+		t.Errorf("expected PORT=abc to be left for manual fixing, got:\n%s", content)
+	}
+
+	result, err = c.verifyProject(context, "", printer, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Variable != "PORT" {
+		t.Errorf("expected only PORT to remain flagged, got %+v", result.Issues)
+	}
+}
+
+func TestVerifyFixReplacesInvalidValueWithDefault(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.development", []byte("PORT=abc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	context := verifyTestContext(map[string]entities.Variable{
+		"PORT": {Name: "PORT", Type: "number", Default: "3000"},
+	})
+	printer := output.NewPrinter(output.FormatTable, true)
+	c := &VerifyCommand{}
+
+	result, err := c.verifyProject(context, "", printer, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.applyFixes(context, result, printer, false)
+
+	data, err := os.ReadFile(".env.development")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# ee verify --fix: replaced invalid value, was: PORT=abc\nPORT=3000\n"
+	if string(data) != want {
+		t.Errorf("got %q, want %q", string(data), want)
+	}
+}
+
+func TestVerifyMasksSecretValues(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.development", []byte("API_PORT=hunter2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	context := verifyTestContext(map[string]entities.Variable{
+		"API_PORT": {Name: "API_PORT", Type: "number", Secret: true},
+	})
+	printer := output.NewPrinter(output.FormatTable, true)
+
+	result, err := (&VerifyCommand{}).verifyProject(context, "", printer, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Issues) != 1 {
+		t.Fatalf("expected one issue, got %+v", result.Issues)
+	}
+
+	issue := result.Issues[0]
+	if strings.Contains(issue.Description, "hunter2") {
+		t.Errorf("description leaks secret value: %s", issue.Description)
+	}
+	if got := issue.displayValue(issue.Actual, false); got != output.SecretMask {
+		t.Errorf("displayValue without reveal = %q, want mask", got)
+	}
+	if got := issue.displayValue(issue.Actual, true); got != "hunter2" {
+		t.Errorf("displayValue with reveal = %q, want real value", got)
+	}
+}
+
+func TestVerifyLoadsSchemaTitle(t *testing.T) {
+	context := verifyTestContext(map[string]entities.Variable{
+		"PORT": {Name: "PORT", Type: "integer"},
+	})
+	context.ProjectConfig.Schema.Title = "API Service"
+	context.ProjectConfig.Schema.Description = strings.Repeat("d", 80)
+
+	result := &VerificationResult{}
+	if _, err := (&VerifyCommand{}).loadProjectSchema(context, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SchemaTitle != "API Service" {
+		t.Errorf("SchemaTitle = %q", result.SchemaTitle)
+	}
+
+	label := schemaLabel(result.SchemaTitle, result.SchemaDescription)
+	if want := "API Service - " + strings.Repeat("d", 57) + "..."; label != want {
+		t.Errorf("schemaLabel = %q, want %q", label, want)
+	}
+}
+
+func TestSummarizeIssues(t *testing.T) {
+	result := &VerificationResult{
+		Issues: []VerificationIssue{
+			{Type: "missing_variable"},
+			{Type: "type_mismatch"},
+			{Type: "type_mismatch"},
+			{Type: "parse_error"},
+		},
+		Warnings: []string{"optional missing"},
+	}
+
+	want := "Summary: 0 missing file(s), 1 missing variable(s), 2 invalid value(s), 1 other issue(s), 1 warning(s)"
+	if got := summarizeIssues(result); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCanonicalValue(t *testing.T) {
+	tests := []struct {
+		typ    string
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{"boolean", "yes", "true", true},
+		{"boolean", "OFF", "false", true},
+		{"boolean", " 1 ", "true", true},
+		{"boolean", "maybe", "", false},
+		{"integer", "3000.0", "3000", true},
+		{"integer", " 8080 ", "8080", true},
+		{"integer", "3000.5", "", false},
+		{"integer", "abc", "", false},
+		{"number", " 1.5", "1.5", true},
+		{"string", "plain", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := canonicalValue(entities.Variable{Name: "V", Type: tt.typ}, tt.value)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("canonicalValue(%s, %q) = (%q, %v), want (%q, %v)",
+				tt.typ, tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestVerifyLayersLocalOverrides(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile(".env.development", []byte("PORT=3000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".env.development.local", []byte("API_KEY=dev-key\nPORT=abc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	context := verifyTestContext(map[string]entities.Variable{
+		"API_KEY": {Name: "API_KEY", Type: "string", Required: true},
+		"PORT":    {Name: "PORT", Type: "number"},
+	})
+	printer := output.NewPrinter(output.FormatTable, true)
+	c := &VerifyCommand{}
+
+	// Without the opt-in the local file is ignored
+	result, err := c.verifyProject(context, "", printer, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Type != "missing_variable" {
+		t.Fatalf("expected only API_KEY to be missing, got %+v", result.Issues)
+	}
+
+	context.ProjectConfig.LocalOverrides = true
+	result, err = c.verifyProject(context, "", printer, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Issues) != 1 {
+		t.Fatalf("expected one issue, got %+v", result.Issues)
+	}
+	issue := result.Issues[0]
+	if issue.Type != "type_mismatch" || issue.Variable != "PORT" || issue.File != ".env.development.local" {
+		t.Errorf("expected PORT mismatch in the local override, got %+v", issue)
+	}
+}
+
+func TestVerifyParseEnvFileChecksEncoding(t *testing.T) {
+	dir := t.TempDir()
+	c := &VerifyCommand{}
+
+	bom := filepath.Join(dir, ".env.bom")
+	if err := os.WriteFile(bom, []byte("\xEF\xBB\xBFPORT=3000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	values, err := c.parseEnvFile(bom)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["PORT"] != "3000" {
+		t.Errorf("BOM not stripped: %q", values)
+	}
+
+	utf16 := filepath.Join(dir, ".env.utf16")
+	if err := os.WriteFile(utf16, []byte("\xFF\xFEP\x00=\x001\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.parseEnvFile(utf16); err == nil || !strings.Contains(err.Error(), "UTF-16") {
+		t.Errorf("expected UTF-16 error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Package command implements the ee version command for reporting build metadata.
+package command
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// BuildInfo describes the running binary. Version, Commit and Date are set
+// via -ldflags at build time.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+}
+
+// VersionCommand handles the ee version command
+type VersionCommand struct {
+	info BuildInfo
+}
+
+// NewVersionCommand creates a new ee version command
+func NewVersionCommand(groupId string, info BuildInfo) *cobra.Command {
+	vc := &VersionCommand{info: withVCSInfo(info)}
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show version and build information",
+		Long: `Show the ee version. With --verbose (or --format json), also show the git
+commit, build date and Go version, which help correlate bug reports with builds.`,
+		Args:    cobra.NoArgs,
+		RunE:    vc.Run,
+		GroupID: groupId,
+	}
+
+	cmd.Flags().BoolP("verbose", "v", false, "Show commit, build date and Go version")
+	cmd.Flags().StringP("format", "f", "text", "Output format: text, json")
+	annotateFormats(cmd, "text", "json")
+
+	return cmd
+}
+
+// Run executes the version command
+func (c *VersionCommand) Run(cmd *cobra.Command, args []string) error {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	format, _ := cmd.Flags().GetString("format")
+	out := cmd.OutOrStdout()
+
+	switch format {
+	case "json":
+		return encodeJSON(out, c.info)
+	case "text":
+		if !verbose {
+			_, err := fmt.Fprintln(out, c.info.Version)
+			return err
+		}
+		_, err := fmt.Fprintf(out, "Version:    %s\nCommit:     %s\nBuilt:      %s\nGo version: %s\n",
+			c.info.Version, c.info.Commit, c.info.Date, c.info.GoVersion)
+		return err
+	default:
+		return fmt.Errorf("unsupported format '%s' (supported: text, json)", format)
+	}
+}
+
+// withVCSInfo fills in the Go version, and the commit and date from the
+// module's VCS stamp when they were not set via -ldflags (e.g. go install)
+func withVCSInfo(info BuildInfo) BuildInfo {
+	info.GoVersion = runtime.Version()
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.Date == "" {
+		info.Date = "unknown"
+	}
+	return info
+}
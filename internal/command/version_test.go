@@ -0,0 +1,43 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestVersionCommandOutput(t *testing.T) {
+	info := BuildInfo{Version: "1.2.3", Commit: "abc123", Date: "2026-01-02T03:04:05Z"}
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := NewVersionCommand("", info)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("version %v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	if got := run(); got != "1.2.3\n" {
+		t.Errorf("plain output = %q, want %q", got, "1.2.3\n")
+	}
+
+	verbose := run("--verbose")
+	for _, want := range []string{"1.2.3", "abc123", "2026-01-02T03:04:05Z", "go"} {
+		if !strings.Contains(verbose, want) {
+			t.Errorf("verbose output missing %q:\n%s", want, verbose)
+		}
+	}
+
+	var decoded BuildInfo
+	if err := json.Unmarshal([]byte(run("-f", "json")), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Version != "1.2.3" || decoded.Commit != "abc123" || decoded.GoVersion == "" {
+		t.Errorf("unexpected JSON output: %+v", decoded)
+	}
+}
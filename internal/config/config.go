@@ -19,6 +19,13 @@ type Config struct {
 
 	// ConfigFile is an optional path to the project config file (default: .ee in cwd)
 	ConfigFile string
+
+	// Settings are the user settings persisted in BaseDir/config.json
+	Settings Settings
+
+	// SettingsLoadError stores any error from loading Settings; commands then
+	// run with empty settings so a broken file can still be fixed
+	SettingsLoadError error
 }
 
 // DefaultConfig returns the default configuration
@@ -58,6 +65,8 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	cfg.Settings, cfg.SettingsLoadError = LoadSettings(cfg.SettingsPath())
+
 	return cfg, nil
 }
 
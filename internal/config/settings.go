@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/n1rna/ee-cli/internal/fsutil"
+)
+
+// SettingsFileName is the name of the user settings file inside BaseDir
+const SettingsFileName = "config.json"
+
+// Setting keys accepted by Settings.Get and Settings.Set
+const (
+	KeyEditor       = "editor"
+	KeyOutputFormat = "output.format"
+)
+
+// SettingKeys lists the keys that can be persisted with ee config set
+var SettingKeys = []string{KeyEditor, KeyOutputFormat}
+
+// OutputFormats lists the values accepted for output.format
+var OutputFormats = []string{"env", "dotenv", "json", "yaml", "csv", "text"}
+
+// Settings holds user settings persisted in BaseDir/config.json
+type Settings struct {
+	Editor string         `json:"editor,omitempty"`
+	Output OutputSettings `json:"output,omitempty"`
+}
+
+// OutputSettings holds output preferences
+type OutputSettings struct {
+	// Format is the default --format for commands that support it
+	Format string `json:"format,omitempty"`
+}
+
+// SettingsPath returns the path of the user settings file
+func (c *Config) SettingsPath() string {
+	return filepath.Join(c.BaseDir, SettingsFileName)
+}
+
+// LoadSettings reads settings from path. A missing file yields empty
+// settings, as does a base directory that is not a directory (e.g. the
+// fallback ".ee" base dir resolving to a project's .ee file).
+func LoadSettings(path string) (Settings, error) {
+	var settings Settings
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("failed to parse settings file %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// SaveSettings writes settings to path, creating its directory if needed
+func SaveSettings(path string, settings Settings) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write settings file: %w", err)
+	}
+	return nil
+}
+
+// Get returns the stored value of a setting key
+func (s *Settings) Get(key string) (string, error) {
+	switch key {
+	case KeyEditor:
+		return s.Editor, nil
+	case KeyOutputFormat:
+		return s.Output.Format, nil
+	default:
+		return "", unknownKeyError(key)
+	}
+}
+
+// Set validates and stores a setting value. An empty value clears the key.
+func (s *Settings) Set(key, value string) error {
+	switch key {
+	case KeyEditor:
+		s.Editor = value
+	case KeyOutputFormat:
+		if value != "" && !slices.Contains(OutputFormats, value) {
+			return fmt.Errorf("invalid %s '%s' (supported: %s)", key, value, strings.Join(OutputFormats, ", "))
+		}
+		s.Output.Format = value
+	default:
+		return unknownKeyError(key)
+	}
+	return nil
+}
+
+// EditorCommand returns the configured editor, falling back to $VISUAL and $EDITOR
+func (s *Settings) EditorCommand() string {
+	if s.Editor != "" {
+		return s.Editor
+	}
+	if visual := os.Getenv("VISUAL"); visual != "" {
+		return visual
+	}
+	return os.Getenv("EDITOR")
+}
+
+// DefaultFormat returns the default output format: $EE_FORMAT, then output.format
+func (s *Settings) DefaultFormat() string {
+	if format := os.Getenv("EE_FORMAT"); format != "" {
+		return format
+	}
+	return s.Output.Format
+}
+
+func unknownKeyError(key string) error {
+	return fmt.Errorf("unknown config key '%s' (valid keys: %s)", key, strings.Join(SettingKeys, ", "))
+}
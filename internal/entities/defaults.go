@@ -0,0 +1,25 @@
+// Package entities provides environment expansion for schema defaults.
+package entities
+
+import "regexp"
+
+// defaultRefPattern matches ${NAME} and ${NAME:-fallback} in schema defaults
+var defaultRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// ExpandDefault expands ${NAME} and ${NAME:-fallback} references in a schema
+// default using lookup. An unset or empty NAME yields the fallback (or an
+// empty string without one). Defaults without references are returned as-is.
+func ExpandDefault(value string, lookup func(string) (string, bool)) string {
+	return defaultRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		match := defaultRefPattern.FindStringSubmatch(ref)
+		if resolved, ok := lookup(match[1]); ok && resolved != "" {
+			return resolved
+		}
+		return match[2]
+	})
+}
+
+// noEnvironment is a lookup with nothing set, so ExpandDefault yields fallbacks
+func noEnvironment(string) (string, bool) {
+	return "", false
+}
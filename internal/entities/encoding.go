@@ -0,0 +1,36 @@
+// Package entities provides encoding checks for files ee reads.
+package entities
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// utf8BOM is the UTF-8 byte order mark some editors prepend to files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// CheckEncoding reports an error when data is not valid UTF-8, naming the
+// first offending line. UTF-16 byte order marks get a dedicated message since
+// they are the most common cause (files saved by Windows editors).
+func CheckEncoding(data []byte) error {
+	if bytes.HasPrefix(data, []byte{0xFF, 0xFE}) || bytes.HasPrefix(data, []byte{0xFE, 0xFF}) {
+		return fmt.Errorf("file is UTF-16 encoded; re-save it as UTF-8")
+	}
+
+	if utf8.Valid(data) {
+		return nil
+	}
+
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if !utf8.Valid(line) {
+			return fmt.Errorf("invalid UTF-8 on line %d; re-save the file as UTF-8", i+1)
+		}
+	}
+	return fmt.Errorf("invalid UTF-8; re-save the file as UTF-8")
+}
+
+// TrimBOM removes a leading UTF-8 byte order mark
+func TrimBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, utf8BOM)
+}
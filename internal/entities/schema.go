@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/n1rna/ee-cli/internal/logger"
 )
 
 // LoadSchemaFromFile loads a schema from a YAML or JSON file.
@@ -19,6 +21,11 @@ func LoadSchemaFromFile(path string) (*Schema, error) {
 		return nil, fmt.Errorf("failed to read schema file %s: %w", path, err)
 	}
 
+	if err := CheckEncoding(data); err != nil {
+		return nil, fmt.Errorf("failed to parse schema file %s: %w", path, err)
+	}
+	data = TrimBOM(data)
+
 	var schema Schema
 
 	ext := strings.ToLower(filepath.Ext(path))
@@ -41,6 +48,7 @@ func LoadSchemaFromFile(path string) (*Schema, error) {
 		schema.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 	}
 
+	logger.Debugf("loaded schema %s (%d variables)", path, len(schema.Variables))
 	return &schema, nil
 }
 
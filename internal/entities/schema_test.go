@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchemaTitleRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	content := `name: api
+title: API Service
+description: Settings for the public API
+variables:
+  - name: PORT
+    type: integer
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := LoadSchemaFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadSchemaFromFile failed: %v", err)
+	}
+	if schema.Title != "API Service" || schema.Description != "Settings for the public API" {
+		t.Fatalf("title/description not loaded: %+v", schema)
+	}
+
+	data, err := yaml.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Schema
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Title != schema.Title || decoded.Description != schema.Description {
+		t.Errorf("round trip lost title/description: %+v", decoded)
+	}
+}
@@ -3,18 +3,29 @@ package entities
 
 // Variable represents a single environment variable definition in the schema
 type Variable struct {
-	Name     string `json:"name"              yaml:"name"`              // Variable name (e.g., DATABASE_URL)
-	Title    string `json:"title,omitempty"   yaml:"title,omitempty"`   // Human-readable title
-	Type     string `json:"type"              yaml:"type"`              // Variable type: string, number, boolean, url
-	Regex    string `json:"regex,omitempty"   yaml:"regex,omitempty"`   // Validation regex pattern
-	Default  string `json:"default,omitempty" yaml:"default,omitempty"` // Default value
-	Required bool   `json:"required"          yaml:"required"`          // Whether variable is required
+	Name     string   `json:"name"              yaml:"name"`              // Variable name (e.g., DATABASE_URL)
+	Title    string   `json:"title,omitempty"   yaml:"title,omitempty"`   // Human-readable title
+	Type     string   `json:"type"              yaml:"type"`              // One of string, number, integer, boolean, url
+	Regex    string   `json:"regex,omitempty"   yaml:"regex,omitempty"`   // Validation regex pattern
+	Default  string   `json:"default,omitempty" yaml:"default,omitempty"` // Default value
+	Required bool     `json:"required"          yaml:"required"`          // Whether variable is required
+	Min      *float64 `json:"min,omitempty"     yaml:"min,omitempty"`     // Minimum value for numeric types
+	Max      *float64 `json:"max,omitempty"     yaml:"max,omitempty"`     // Maximum value for numeric types
+	Secret   bool     `json:"secret,omitempty"  yaml:"secret,omitempty"`  // Whether the value is masked in output
+
+	// AllowedSchemes restricts url types to the listed schemes (e.g., https, postgres)
+	AllowedSchemes []string `json:"allowed_schemes,omitempty" yaml:"allowed_schemes,omitempty"`
 }
 
 // Schema represents a schema definition loaded from a file
 type Schema struct {
 	Name        string     `json:"name"                  yaml:"name"`
+	Title       string     `json:"title,omitempty"       yaml:"title,omitempty"`
 	Description string     `json:"description,omitempty" yaml:"description,omitempty"`
 	Variables   []Variable `json:"variables"             yaml:"variables"`
 	Extends     []string   `json:"extends,omitempty"     yaml:"extends,omitempty"`
+
+	// ExpandDefaults enables ${NAME:-fallback} references in variable
+	// defaults, resolved against the host environment when values are hydrated
+	ExpandDefaults bool `json:"expand_defaults,omitempty" yaml:"expand_defaults,omitempty"`
 }
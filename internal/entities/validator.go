@@ -3,43 +3,114 @@ package entities
 
 import (
 	"fmt"
+	"math"
+	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 )
 
+// regexCacheSize bounds the number of compiled patterns kept in memory
+const regexCacheSize = 256
+
+// regexCache holds compiled regex patterns shared by all Validator instances,
+// so validators created per sheet don't recompile the same schema patterns
+var regexCache = struct {
+	sync.Mutex
+	patterns map[string]*regexp.Regexp
+}{
+	patterns: make(map[string]*regexp.Regexp),
+}
+
+// onRegexCompile, when set, is called for every pattern compiled into the
+// cache. Only tests set it.
+var onRegexCompile func(pattern string)
+
+// compileRegex returns the compiled pattern from the shared cache, compiling
+// and caching it on first use. When the cache is full an arbitrary entry is
+// evicted to keep memory bounded.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	regexCache.Lock()
+	defer regexCache.Unlock()
+
+	if compiled, exists := regexCache.patterns[pattern]; exists {
+		return compiled, nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if onRegexCompile != nil {
+		onRegexCompile(pattern)
+	}
+
+	if len(regexCache.patterns) >= regexCacheSize {
+		for key := range regexCache.patterns {
+			delete(regexCache.patterns, key)
+			break
+		}
+	}
+	regexCache.patterns[pattern] = compiled
+
+	return compiled, nil
+}
+
+// variableNamePattern matches names that are valid shell environment variables
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateVariableName checks that name can be exported by a shell: letters,
+// digits and underscores, not starting with a digit
+func ValidateVariableName(name string) error {
+	if !variableNamePattern.MatchString(name) {
+		return fmt.Errorf(
+			"invalid variable name %q: must start with a letter or underscore and contain only letters, digits and underscores",
+			name,
+		)
+	}
+	return nil
+}
+
 // Validator handles schema validation logic
 type Validator struct {
-	compiledRegexes map[string]*regexp.Regexp
+	// AllowInvalidNames skips the shell naming check for legacy schemas
+	AllowInvalidNames bool
 }
 
 // NewValidator creates a new validator instance
 func NewValidator() *Validator {
-	return &Validator{
-		compiledRegexes: make(map[string]*regexp.Regexp),
-	}
+	return &Validator{}
 }
 
-// validateVariable checks if a variable definition is valid
-func (v *Validator) validateVariable(variable *Variable) error {
+// ValidateVariable checks if a variable definition is valid, including its default
+func (v *Validator) ValidateVariable(variable *Variable) error {
 	if variable.Name == "" {
 		return fmt.Errorf("variable name cannot be empty")
 	}
+	if !v.AllowInvalidNames {
+		if err := ValidateVariableName(variable.Name); err != nil {
+			return err
+		}
+	}
 
 	// Validate type
 	switch variable.Type {
-	case "string", "number", "boolean", "url":
+	case "string", "number", "integer", "boolean", "url":
 		// Valid types
 	default:
 		return fmt.Errorf("unsupported type: %s", variable.Type)
 	}
 
+	// Validate numeric bounds
+	if variable.Min != nil && variable.Max != nil && *variable.Min > *variable.Max {
+		return fmt.Errorf("min (%g) cannot be greater than max (%g)", *variable.Min, *variable.Max)
+	}
+
 	// Compile and validate regex if provided
 	if variable.Regex != "" {
-		if _, exists := v.compiledRegexes[variable.Regex]; !exists {
-			compiled, err := regexp.Compile(variable.Regex)
-			if err != nil {
-				return fmt.Errorf("invalid regex pattern: %w", err)
-			}
-			v.compiledRegexes[variable.Regex] = compiled
+		if _, err := compileRegex(variable.Regex); err != nil {
+			return fmt.Errorf("invalid regex pattern: %w", err)
 		}
 	}
 
@@ -61,22 +132,101 @@ func (v *Validator) ValidateValue(variable *Variable, value string) error {
 
 	switch variable.Type {
 	case "number":
-		// TODO: Implement number parsing and validation
+		if value != "" {
+			if err := v.validateNumber(variable, value); err != nil {
+				return err
+			}
+		}
+	case "integer":
+		if value != "" {
+			if err := v.validateInteger(variable, value); err != nil {
+				return err
+			}
+		}
 	case "boolean":
 		if value != "true" && value != "false" {
 			return fmt.Errorf("boolean value must be 'true' or 'false'")
 		}
 	case "url":
-		// TODO: Implement URL validation
+		if value != "" {
+			if err := v.validateURL(variable, value); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Check regex pattern if defined
 	if variable.Regex != "" {
-		if regex, exists := v.compiledRegexes[variable.Regex]; exists {
-			if !regex.MatchString(value) {
-				return fmt.Errorf("value does not match regex pattern")
+		regex, err := compileRegex(variable.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex pattern: %w", err)
+		}
+		if !regex.MatchString(value) {
+			return fmt.Errorf("value does not match regex pattern")
+		}
+	}
+
+	return nil
+}
+
+// validateNumber parses a number value and enforces the variable's min/max bounds
+func (v *Validator) validateNumber(variable *Variable, value string) error {
+	number, err := strconv.ParseFloat(value, 64)
+	// ParseFloat also accepts NaN, Inf and hex floats; NaN would slip past
+	// min/max since every comparison with it is false
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) || isHexFloat(value) {
+		return fmt.Errorf("value %q is not a valid number", value)
+	}
+
+	return v.checkBounds(variable, value, number)
+}
+
+// isHexFloat reports whether value uses Go's hexadecimal float syntax (0x1p4)
+func isHexFloat(value string) bool {
+	value = strings.TrimLeft(value, "+-")
+	return len(value) > 1 && value[0] == '0' && (value[1] == 'x' || value[1] == 'X')
+}
+
+// validateInteger parses an integer value and enforces the variable's min/max bounds
+func (v *Validator) validateInteger(variable *Variable, value string) error {
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("value %q is not a valid integer", value)
+	}
+
+	return v.checkBounds(variable, value, float64(number))
+}
+
+// checkBounds enforces the variable's min/max bounds on a parsed numeric value
+func (v *Validator) checkBounds(variable *Variable, value string, number float64) error {
+	if variable.Min != nil && number < *variable.Min {
+		return fmt.Errorf("value %q is less than minimum %g", value, *variable.Min)
+	}
+	if variable.Max != nil && number > *variable.Max {
+		return fmt.Errorf("value %q is greater than maximum %g", value, *variable.Max)
+	}
+
+	return nil
+}
+
+// validateURL parses a URL value and enforces the variable's allowed schemes
+func (v *Validator) validateURL(variable *Variable, value string) error {
+	parsed, err := url.ParseRequestURI(value)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("value %q is not a valid URL", value)
+	}
+
+	if len(variable.AllowedSchemes) > 0 {
+		for _, scheme := range variable.AllowedSchemes {
+			if strings.EqualFold(parsed.Scheme, scheme) {
+				return nil
 			}
 		}
+		return fmt.Errorf(
+			"URL scheme %q is not allowed (allowed: %s)",
+			parsed.Scheme,
+			strings.Join(variable.AllowedSchemes, ", "),
+		)
 	}
 
 	return nil
@@ -89,10 +239,54 @@ func (v *Validator) ValidateSchema(schema *Schema) error {
 	}
 
 	for _, variable := range schema.Variables {
-		if err := v.validateVariable(&variable); err != nil {
+		if err := v.validateSchemaVariable(schema, variable); err != nil {
 			return fmt.Errorf("invalid variable %s: %w", variable.Name, err)
 		}
 	}
 
 	return nil
 }
+
+// SchemaIssue describes one problem found in a schema. Variable is empty for
+// problems with the schema itself.
+type SchemaIssue struct {
+	Variable string `json:"variable,omitempty"`
+	Message  string `json:"message"`
+}
+
+// SchemaIssues checks every variable of a schema and returns all problems
+// found, in variable order, instead of stopping at the first one like
+// ValidateSchema. Duplicate variable names are reported as well.
+func (v *Validator) SchemaIssues(schema *Schema) []SchemaIssue {
+	var issues []SchemaIssue
+	if schema.Name == "" {
+		issues = append(issues, SchemaIssue{Message: "schema name cannot be empty"})
+	}
+
+	seen := make(map[string]bool, len(schema.Variables))
+	for _, variable := range schema.Variables {
+		if variable.Name != "" && seen[variable.Name] {
+			issues = append(issues, SchemaIssue{
+				Variable: variable.Name,
+				Message:  "variable is defined more than once",
+			})
+			continue
+		}
+		seen[variable.Name] = true
+
+		if err := v.validateSchemaVariable(schema, variable); err != nil {
+			issues = append(issues, SchemaIssue{Variable: variable.Name, Message: err.Error()})
+		}
+	}
+
+	return issues
+}
+
+// validateSchemaVariable validates a variable in the context of its schema
+func (v *Validator) validateSchemaVariable(schema *Schema, variable Variable) error {
+	// Expandable defaults are only known at hydrate time; check the fallback
+	if schema.ExpandDefaults {
+		variable.Default = ExpandDefault(variable.Default, noEnvironment)
+	}
+	return v.ValidateVariable(&variable)
+}
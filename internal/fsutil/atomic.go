@@ -0,0 +1,105 @@
+// Package fsutil provides filesystem helpers shared across ee packages.
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/n1rna/ee-cli/internal/logger"
+)
+
+// WriteFileAtomic writes data to path via a temporary file in the same
+// directory that is renamed into place, so readers never observe a partially
+// written file and a failed write leaves the previous contents intact.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return WriteFileAtomicFunc(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// WriteFileAtomicFunc is like WriteFileAtomic but streams the contents through
+// write. If write returns an error the target file is left untouched.
+//
+// Like os.WriteFile, a symlinked path is written through to its target, an
+// existing file keeps its mode and a new file gets perm (before umask).
+func WriteFileAtomicFunc(path string, perm os.FileMode, write func(io.Writer) error) error {
+	path, err := resolveSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := createTemp(path, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// Remove the temporary file on any failure; after a successful rename
+	// this is a no-op
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if info, err := os.Stat(path); err == nil {
+		if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to set file permissions: %w", err)
+		}
+	}
+	if err := write(tmp); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to flush %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	committed = true
+	logger.Debugf("wrote %s", path)
+	return nil
+}
+
+// resolveSymlinks returns the file path ultimately points at, so renaming
+// over it replaces the link target rather than the link. A path that does not
+// exist yet is returned unchanged.
+func resolveSymlinks(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return path, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return resolved, nil
+}
+
+// createTemp creates a uniquely named temporary file next to path. Unlike
+// os.CreateTemp it opens the file with perm, so the umask applies as it does
+// for os.WriteFile.
+func createTemp(path string, perm os.FileMode) (*os.File, error) {
+	prefix := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	for range 10000 {
+		name := prefix + strconv.FormatUint(uint64(rand.Uint32()), 10)
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return file, err
+	}
+	return nil, fmt.Errorf("too many attempts to create a temporary file for %s", path)
+}
@@ -0,0 +1,121 @@
+package fsutil
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomicFuncKeepsPreviousContentsOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".ee")
+	if err := os.WriteFile(path, []byte(`{"project":"app"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash mid-write: some bytes go out, then the writer fails
+	errPartial := errors.New("disk full")
+	err := WriteFileAtomicFunc(path, 0o644, func(w io.Writer) error {
+		if _, err := w.Write([]byte(`{"proj`)); err != nil {
+			return err
+		}
+		return errPartial
+	})
+	if !errors.Is(err, errPartial) {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"project":"app"}` {
+		t.Errorf("previous contents lost: %q", data)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary file left behind: %v", entries)
+	}
+}
+
+func TestWriteFileAtomicReplacesContents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("OLD=1\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("NEW=2\n"), 0o600); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "NEW=2\n" {
+		t.Errorf("contents = %q", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o640 {
+		t.Errorf("permissions = %o, want the existing 640", perm)
+	}
+}
+
+func TestWriteFileAtomicCreatesWithPerm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+
+	if err := WriteFileAtomic(path, []byte("NEW=2\n"), 0o600); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+}
+
+func TestWriteFileAtomicWritesThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "shared", "dev.env")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("DEBUG=yes\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, ".env.dev")
+	if err := os.Symlink(filepath.Join("shared", "dev.env"), link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := WriteFileAtomic(link, []byte("DEBUG=true\n"), 0o644); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Error("symlink was replaced by a regular file")
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "DEBUG=true\n" {
+		t.Errorf("target contents = %q", data)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -41,6 +42,9 @@ type Logger struct {
 var (
 	defaultLogger *Logger
 	once          sync.Once
+
+	// debugLogger receives package-level Debugf calls; nil unless EnableDebug was called
+	debugLogger atomic.Pointer[Logger]
 )
 
 // GetLogger returns the default logger instance
@@ -193,6 +197,10 @@ func (l *Logger) Errorf(format string, args ...interface{}) {
 // Global convenience functions that use the default logger
 
 func Debugf(format string, args ...interface{}) {
+	if l := debugLogger.Load(); l != nil {
+		l.Debugf(format, args...)
+		return
+	}
 	GetLogger().Debugf(format, args...)
 }
 
@@ -212,3 +220,23 @@ func Errorf(format string, args ...interface{}) {
 func SetGlobalLevel(level LogLevel) {
 	GetLogger().SetLevel(level)
 }
+
+// EnableDebug sends package-level debug messages to w (stderr for the CLI).
+// Debug lines never reach the default logger's outputs, so normal command
+// output is unchanged.
+func EnableDebug(w io.Writer) {
+	l := NewLogger(DEBUG)
+	l.SetShowFile(false)
+	l.AddOutput(DEBUG, w)
+	debugLogger.Store(l)
+}
+
+// DisableDebug turns package-level debug messages off again
+func DisableDebug() {
+	debugLogger.Store(nil)
+}
+
+// DebugEnabled reports whether EnableDebug is in effect
+func DebugEnabled() bool {
+	return debugLogger.Load() != nil
+}
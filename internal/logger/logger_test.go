@@ -119,3 +119,29 @@ func TestShowFile(t *testing.T) {
 		t.Error("Expected no file information in log")
 	}
 }
+
+func TestEnableDebug(t *testing.T) {
+	defer DisableDebug()
+
+	var buf bytes.Buffer
+	Debugf("before %s", "enable")
+	if DebugEnabled() {
+		t.Fatal("expected debug to be disabled by default")
+	}
+
+	EnableDebug(&buf)
+	Debugf("loaded %s", ".ee")
+	if !strings.Contains(buf.String(), "[DEBUG] loaded .ee") {
+		t.Errorf("expected debug line, got %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "before") {
+		t.Errorf("message logged before EnableDebug leaked: %q", buf.String())
+	}
+
+	DisableDebug()
+	buf.Reset()
+	Debugf("after disable")
+	if buf.Len() != 0 {
+		t.Errorf("expected no output after DisableDebug, got %q", buf.String())
+	}
+}
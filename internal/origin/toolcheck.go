@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
+
+	"github.com/n1rna/ee-cli/internal/logger"
 )
 
 var toolInstallHints = map[string]string{
@@ -95,6 +98,9 @@ func ToolCommand(name string, args ...string) (*exec.Cmd, error) {
 		return nil, err
 	}
 	full := append(argv, args...)
+	// Arguments may carry secret values (e.g. gh secret set --body), so only
+	// the resolved tool is logged
+	logger.Debugf("running %s with %d arguments", strings.Join(argv, " "), len(args))
 	return exec.Command(full[0], full[1:]...), nil
 }
 
@@ -104,5 +110,9 @@ func RunCommand(name string, args ...string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return cmd.CombinedOutput()
+
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	logger.Debugf("%s finished in %s (err: %v)", name, time.Since(start).Round(time.Millisecond), err)
+	return out, err
 }
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+// ColorMode controls whether output is colored
+type ColorMode string
+
+const (
+	// ColorAuto colors output only when stdout is a terminal and NO_COLOR is
+	// not set
+	ColorAuto ColorMode = "auto"
+	// ColorAlways colors output even when it is piped or redirected
+	ColorAlways ColorMode = "always"
+	// ColorNever never colors output
+	ColorNever ColorMode = "never"
+)
+
+// stdoutIsTerminal reports whether stdout is a terminal; a variable so tests
+// can simulate either case
+var stdoutIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// colorDisabledByEnv reports whether the NO_COLOR convention
+// (https://no-color.org) asks for uncolored output
+func colorDisabledByEnv() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// ConfigureColor enables or disables colored output for all printers
+func ConfigureColor(mode ColorMode) error {
+	switch mode {
+	case ColorAlways:
+		pterm.EnableColor()
+	case ColorNever:
+		pterm.DisableColor()
+	case ColorAuto:
+		if stdoutIsTerminal() && !colorDisabledByEnv() {
+			pterm.EnableColor()
+		} else {
+			pterm.DisableColor()
+		}
+	default:
+		return fmt.Errorf("invalid color mode '%s' (supported: auto, always, never)", mode)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/pterm/pterm"
+)
+
+func TestConfigureColor(t *testing.T) {
+	origTerminal := stdoutIsTerminal
+	t.Cleanup(func() {
+		stdoutIsTerminal = origTerminal
+		pterm.EnableColor()
+	})
+
+	tests := []struct {
+		mode     ColorMode
+		terminal bool
+		want     bool
+	}{
+		{ColorAuto, true, true},
+		{ColorAuto, false, false},
+		{ColorAlways, false, true},
+		{ColorNever, true, false},
+	}
+
+	for _, tt := range tests {
+		stdoutIsTerminal = func() bool { return tt.terminal }
+		if err := ConfigureColor(tt.mode); err != nil {
+			t.Fatalf("ConfigureColor(%s) failed: %v", tt.mode, err)
+		}
+		if pterm.PrintColor != tt.want {
+			t.Errorf("mode %s, terminal %v: PrintColor = %v, want %v",
+				tt.mode, tt.terminal, pterm.PrintColor, tt.want)
+		}
+	}
+
+	if err := ConfigureColor("sometimes"); err == nil {
+		t.Error("expected an error for an invalid mode")
+	}
+}
+
+func TestConfigureColorRespectsNoColor(t *testing.T) {
+	origTerminal := stdoutIsTerminal
+	t.Cleanup(func() {
+		stdoutIsTerminal = origTerminal
+		pterm.EnableColor()
+	})
+	stdoutIsTerminal = func() bool { return true }
+	t.Setenv("NO_COLOR", "1")
+
+	if err := ConfigureColor(ColorAuto); err != nil {
+		t.Fatal(err)
+	}
+	if pterm.PrintColor {
+		t.Error("NO_COLOR should disable color in auto mode")
+	}
+
+	if err := ConfigureColor(ColorAlways); err != nil {
+		t.Fatal(err)
+	}
+	if !pterm.PrintColor {
+		t.Error("--color always should override NO_COLOR")
+	}
+}
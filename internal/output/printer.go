@@ -3,6 +3,7 @@
 package output
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
 )
 
 // Format represents different output formats
@@ -23,31 +25,81 @@ const (
 	FormatCSV   Format = "csv"
 )
 
+// SecretMask replaces secret values in human-readable output
+const SecretMask = "****"
+
+var (
+	// exportEscaper escapes quotes in values written as shell export statements
+	exportEscaper = strings.NewReplacer("\"", "\\\"")
+
+	// dotEnvEscaper escapes backslashes, quotes and newlines in .env values
+	dotEnvEscaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n")
+)
+
 // Printer handles formatted output to the terminal
 type Printer struct {
-	writer io.Writer
-	format Format
-	quiet  bool
+	writer            io.Writer
+	messages          io.Writer
+	format            Format
+	quiet             bool
+	secrets           map[string]bool
+	noTrailingNewline bool
+	keyOrder          []string
 }
 
 // NewPrinter creates a new printer with the specified format
 func NewPrinter(format Format, quiet bool) *Printer {
 	return &Printer{
-		writer: os.Stdout,
-		format: format,
-		quiet:  quiet,
+		writer:   os.Stdout,
+		messages: messageWriter(format),
+		format:   format,
+		quiet:    quiet,
 	}
 }
 
 // NewPrinterWithWriter creates a new printer with a custom writer
 func NewPrinterWithWriter(writer io.Writer, format Format, quiet bool) *Printer {
 	return &Printer{
-		writer: writer,
-		format: format,
-		quiet:  quiet,
+		writer:   writer,
+		messages: messageWriter(format),
+		format:   format,
+		quiet:    quiet,
+	}
+}
+
+// messageWriter returns where status messages go for format. JSON output
+// sends them to stderr so stdout carries only the JSON document; nil keeps
+// pterm's default of stdout.
+func messageWriter(format Format) io.Writer {
+	if format == FormatJSON {
+		return os.Stderr
+	}
+	return nil
+}
+
+// SetSecrets marks variable names whose values PrintValues masks (table,
+// JSON, YAML and CSV). The export formats (PrintDotEnv,
+// PrintEnvironmentExport) are for machine consumption and always print real
+// values.
+func (p *Printer) SetSecrets(names []string) {
+	p.secrets = make(map[string]bool, len(names))
+	for _, name := range names {
+		p.secrets[name] = true
 	}
 }
 
+// SetNoTrailingNewline omits the newline after the last line of env/dotenv
+// output, for consumers that are strict about trailing newlines
+func (p *Printer) SetNoTrailingNewline(omit bool) {
+	p.noTrailingNewline = omit
+}
+
+// SetKeyOrder makes env/dotenv output list these keys first, in the given
+// order, followed by any remaining keys sorted by name
+func (p *Printer) SetKeyOrder(keys []string) {
+	p.keyOrder = keys
+}
+
 // printf is a helper that handles fmt.Fprintf errors
 func (p *Printer) printf(format string, args ...interface{}) {
 	_, _ = fmt.Fprintf(p.writer, format, args...)
@@ -56,40 +108,40 @@ func (p *Printer) printf(format string, args ...interface{}) {
 // Success prints a success message
 func (p *Printer) Success(message string) {
 	if !p.quiet {
-		pterm.Success.Println(message)
+		pterm.Success.WithWriter(p.messages).Println(message)
 	}
 }
 
 // Error prints an error message
 func (p *Printer) Error(message string) {
-	pterm.Error.Println(message)
+	pterm.Error.WithWriter(p.messages).Println(message)
 }
 
 // Warning prints a warning message
 func (p *Printer) Warning(message string) {
 	if !p.quiet {
-		pterm.Warning.Println(message)
+		pterm.Warning.WithWriter(p.messages).Println(message)
 	}
 }
 
 // Info prints an informational message
 func (p *Printer) Info(message string) {
 	if !p.quiet {
-		pterm.Info.Println(message)
+		pterm.Info.WithWriter(p.messages).Println(message)
 	}
 }
 
 // Printf prints a formatted message
 func (p *Printer) Printf(format string, args ...interface{}) {
 	if !p.quiet {
-		pterm.Printf(format, args...)
+		pterm.Fprint(p.messages, pterm.Sprintf(format, args...))
 	}
 }
 
 // Println prints a line
 func (p *Printer) Println(message string) {
 	if !p.quiet {
-		pterm.Println(message)
+		pterm.Fprintln(p.messages, message)
 	}
 }
 
@@ -101,7 +153,7 @@ func (p *Printer) Sprintf(format string, args ...interface{}) string {
 // Debug prints a debug message
 func (p *Printer) Debug(message string) {
 	if !p.quiet {
-		pterm.Debug.Println(message)
+		pterm.Debug.WithWriter(p.messages).Println(message)
 	}
 }
 
@@ -129,11 +181,16 @@ func (p *Printer) PrintUpdate(message string) {
 
 // PrintValues prints environment variable values
 func (p *Printer) PrintValues(values map[string]string) error {
+	values = p.maskSecrets(values)
 	switch p.format {
 	case FormatTable:
 		return p.printValuesTable(values)
 	case FormatJSON:
 		return p.printJSON(values)
+	case FormatYAML:
+		return p.printYAML(values)
+	case FormatCSV:
+		return p.printValuesCSV(values)
 	default:
 		return fmt.Errorf("unsupported format: %s", p.format)
 	}
@@ -170,48 +227,134 @@ func (p *Printer) printValuesTable(values map[string]string) error {
 	return pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
 }
 
-// printJSON prints any object as JSON
-func (p *Printer) printJSON(obj interface{}) error {
-	encoder := json.NewEncoder(p.writer)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(obj)
+// maskSecrets returns values with every secret replaced by SecretMask
+func (p *Printer) maskSecrets(values map[string]string) map[string]string {
+	if len(p.secrets) == 0 {
+		return values
+	}
+
+	masked := make(map[string]string, len(values))
+	for key, value := range values {
+		if p.secrets[key] {
+			value = SecretMask
+		}
+		masked[key] = value
+	}
+	return masked
 }
 
-// PrintEnvironmentExport prints environment variables in export format
-func (p *Printer) PrintEnvironmentExport(values map[string]string) error {
-	// Sort keys for consistent output
+// printValuesCSV prints variable values as CSV with the same columns as the table output
+func (p *Printer) printValuesCSV(values map[string]string) error {
 	keys := make([]string, 0, len(values))
 	for key := range values {
 		keys = append(keys, key)
 	}
 	sort.Strings(keys)
 
+	writer := csv.NewWriter(p.writer)
+	if err := writer.Write([]string{"VARIABLE", "VALUE"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
 	for _, key := range keys {
-		value := values[key]
-		// Escape quotes in value
-		value = strings.ReplaceAll(value, "\"", "\\\"")
-		p.printf("export %s=\"%s\"\n", key, value)
+		if err := writer.Write([]string{key, values[key]}); err != nil {
+			return fmt.Errorf("failed to write CSV row for %s: %w", key, err)
+		}
 	}
+	writer.Flush()
+	return writer.Error()
+}
 
-	return nil
+// printJSON prints any object as JSON
+func (p *Printer) printJSON(obj interface{}) error {
+	encoder := json.NewEncoder(p.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(obj)
+}
+
+// printYAML prints any object as YAML
+func (p *Printer) printYAML(obj interface{}) error {
+	encoder := yaml.NewEncoder(p.writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(obj); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// PrintEnvironmentExport prints environment variables in export format
+func (p *Printer) PrintEnvironmentExport(values map[string]string) error {
+	return p.printAssignments("export ", exportEscaper, values, nil)
+}
+
+// PrintEnvironmentExportWithSources prints export statements, each annotated
+// with a trailing comment naming the source the value came from
+func (p *Printer) PrintEnvironmentExportWithSources(values, sources map[string]string) error {
+	return p.printAssignments("export ", exportEscaper, values, sources)
 }
 
 // PrintDotEnv prints environment variables in .env format
 func (p *Printer) PrintDotEnv(values map[string]string) error {
-	// Sort keys for consistent output
+	return p.printAssignments("", dotEnvEscaper, values, nil)
+}
+
+// PrintDotEnvWithSources prints .env lines, each annotated with a trailing
+// comment naming the source the value came from
+func (p *Printer) PrintDotEnvWithSources(values, sources map[string]string) error {
+	return p.printAssignments("", dotEnvEscaper, values, sources)
+}
+
+// PrintCommentBanner prints lines as a block of # comments, to head env or
+// dotenv output. Nothing is printed for no lines.
+func (p *Printer) PrintCommentBanner(lines []string) {
+	for _, line := range lines {
+		p.printf("# %s\n", line)
+	}
+}
+
+// orderedKeys returns the keys of values in output order: the SetKeyOrder
+// keys that are present, then the rest sorted for consistent output
+func (p *Printer) orderedKeys(values map[string]string) []string {
 	keys := make([]string, 0, len(values))
+	listed := make(map[string]bool, len(p.keyOrder))
+	for _, key := range p.keyOrder {
+		if _, ok := values[key]; ok && !listed[key] {
+			keys = append(keys, key)
+			listed[key] = true
+		}
+	}
+
+	rest := make([]string, 0, len(values)-len(keys))
 	for key := range values {
-		keys = append(keys, key)
+		if !listed[key] {
+			rest = append(rest, key)
+		}
 	}
-	sort.Strings(keys)
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
 
-	for _, key := range keys {
-		value := values[key]
-		// Escape quotes and newlines in value
-		value = strings.ReplaceAll(value, "\\", "\\\\")
-		value = strings.ReplaceAll(value, "\"", "\\\"")
-		value = strings.ReplaceAll(value, "\n", "\\n")
-		p.printf("%s=\"%s\"\n", key, value)
+// printAssignments prints KEY="VALUE" lines in orderedKeys order. Values are streamed
+// through the escaper straight to the writer so large values are never
+// copied in memory. When sources is non-nil, each line gets a "# from" comment.
+func (p *Printer) printAssignments(
+	prefix string,
+	escaper *strings.Replacer,
+	values map[string]string,
+	sources map[string]string,
+) error {
+	keys := p.orderedKeys(values)
+	for i, key := range keys {
+		p.printf("%s%s=\"", prefix, key)
+		if _, err := escaper.WriteString(p.writer, values[key]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		p.printf("\"")
+		if source, ok := sources[key]; ok {
+			p.printf(" # from %s", source)
+		}
+		if !p.noTrailingNewline || i < len(keys)-1 {
+			p.printf("\n")
+		}
 	}
 
 	return nil
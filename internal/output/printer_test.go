@@ -0,0 +1,272 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingWriter counts bytes written without retaining them.
+type countingWriter struct {
+	n int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
+
+func (w *countingWriter) WriteString(s string) (int, error) {
+	w.n += len(s)
+	return len(s), nil
+}
+
+func TestPrintDotEnvEscapesValues(t *testing.T) {
+	var buf bytes.Buffer
+	printer := NewPrinterWithWriter(&buf, FormatTable, false)
+
+	err := printer.PrintDotEnv(map[string]string{
+		"B": "say \"hi\"\nbye",
+		"A": `C:\path`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "A=\"C:\\\\path\"\nB=\"say \\\"hi\\\"\\nbye\"\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintEnvironmentExportEscapesQuotes(t *testing.T) {
+	var buf bytes.Buffer
+	printer := NewPrinterWithWriter(&buf, FormatTable, false)
+
+	if err := printer.PrintEnvironmentExport(map[string]string{"A": `a "b"`}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "export A=\"a \\\"b\\\"\"\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintDotEnvStreamsLargeValues(t *testing.T) {
+	const size = 8 << 20
+	value := strings.Repeat("a", size/2) + "\"" + strings.Repeat("b", size/2)
+	values := map[string]string{"LARGE": value}
+
+	w := &countingWriter{}
+	printer := NewPrinterWithWriter(w, FormatTable, false)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	if err := printer.PrintDotEnv(values); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runtime.ReadMemStats(&after)
+
+	// LARGE="<value with one escaped quote>"\n
+	if want := len("LARGE=\"") + len(value) + 1 + len("\"\n"); w.n != want {
+		t.Errorf("wrote %d bytes, want %d", w.n, want)
+	}
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > size/4 {
+		t.Errorf("allocated %d bytes for a %d byte value; expected streaming output", allocated, size)
+	}
+}
+
+func TestPrintValuesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	printer := NewPrinterWithWriter(&buf, FormatCSV, false)
+
+	err := printer.PrintValues(map[string]string{
+		"GREETING": `hello, "world"`,
+		"API_URL":  "https://example.com",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "VARIABLE,VALUE\nAPI_URL,https://example.com\nGREETING,\"hello, \"\"world\"\"\"\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintValuesYAML(t *testing.T) {
+	var buf bytes.Buffer
+	printer := NewPrinterWithWriter(&buf, FormatYAML, false)
+
+	err := printer.PrintValues(map[string]string{
+		"PORT":     "3000",
+		"DEBUG":    "true",
+		"API_URL":  "https://example.com",
+		"GREETING": "hello: world",
+		"EMPTY":    "",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Keys are sorted; values that YAML would read as another type or that
+	// contain YAML syntax are quoted so they round-trip as strings
+	want := "API_URL: https://example.com\n" +
+		"DEBUG: \"true\"\n" +
+		"EMPTY: \"\"\n" +
+		"GREETING: 'hello: world'\n" +
+		"PORT: \"3000\"\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintValuesMasksSecrets(t *testing.T) {
+	values := map[string]string{"API_KEY": "sk-live-123", "PORT": "3000"}
+
+	for _, format := range []Format{FormatJSON, FormatCSV} {
+		var buf bytes.Buffer
+		printer := NewPrinterWithWriter(&buf, format, false)
+		printer.SetSecrets([]string{"API_KEY"})
+		if err := printer.PrintValues(values); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if strings.Contains(buf.String(), "sk-live-123") || !strings.Contains(buf.String(), SecretMask) {
+			t.Errorf("%s: expected API_KEY to be masked, got %q", format, buf.String())
+		}
+		if !strings.Contains(buf.String(), "3000") {
+			t.Errorf("%s: expected PORT to be shown, got %q", format, buf.String())
+		}
+	}
+
+	// Export formats are for machines and keep real values
+	var buf bytes.Buffer
+	printer := NewPrinterWithWriter(&buf, FormatTable, false)
+	printer.SetSecrets([]string{"API_KEY"})
+	if err := printer.PrintDotEnv(values); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `API_KEY="sk-live-123"`) {
+		t.Errorf("expected dotenv output to keep the real value, got %q", buf.String())
+	}
+}
+
+func TestPrintDotEnvWithSources(t *testing.T) {
+	var buf bytes.Buffer
+	printer := NewPrinterWithWriter(&buf, FormatTable, false)
+
+	err := printer.PrintDotEnvWithSources(
+		map[string]string{"A": "1", "B": "2"},
+		map[string]string{"A": ".env.development", "B": "stdin"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "A=\"1\" # from .env.development\nB=\"2\" # from stdin\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintDotEnvTrailingNewline(t *testing.T) {
+	values := map[string]string{"B": "2", "A": "1"}
+
+	var buf bytes.Buffer
+	printer := NewPrinterWithWriter(&buf, FormatTable, false)
+	if err := printer.PrintDotEnv(values); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "A=\"1\"\nB=\"2\"\n"; buf.String() != want {
+		t.Errorf("default: got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	printer.SetNoTrailingNewline(true)
+	if err := printer.PrintDotEnv(values); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "A=\"1\"\nB=\"2\""; buf.String() != want {
+		t.Errorf("no trailing newline: got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := printer.PrintEnvironmentExport(values); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "export A=\"1\"\nexport B=\"2\""; buf.String() != want {
+		t.Errorf("export: got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintSummaryJSON(t *testing.T) {
+	var buf bytes.Buffer
+	printer := NewPrinterWithWriter(&buf, FormatJSON, true)
+
+	summary := OperationSummary{Created: 2, Updated: 1, Skipped: 3, Failed: 1, Duration: 1500 * time.Millisecond}
+	if err := printer.PrintSummary(summary); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "{\n  \"created\": 2,\n  \"updated\": 1,\n  \"skipped\": 3,\n  \"failed\": 1,\n  \"duration_ms\": 1500\n}\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if summary.Total() != 7 {
+		t.Errorf("Total() = %d, want 7", summary.Total())
+	}
+}
+
+func TestJSONPrinterSendsMessagesToStderr(t *testing.T) {
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = stderr.Close() }()
+
+	orig := os.Stderr
+	os.Stderr = stderr
+	defer func() { os.Stderr = orig }()
+
+	var buf bytes.Buffer
+	printer := NewPrinterWithWriter(&buf, FormatJSON, false)
+	printer.Success("wrote development.env")
+	printer.Error("production failed")
+	if err := printer.PrintSummary(OperationSummary{Created: 1, Failed: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "{") || strings.Contains(buf.String(), "development.env") {
+		t.Errorf("stdout should hold only the JSON summary, got %q", buf.String())
+	}
+	data, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"wrote development.env", "production failed"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("stderr = %q, missing %q", data, want)
+		}
+	}
+}
+
+func TestPrintDotEnvKeyOrder(t *testing.T) {
+	var buf bytes.Buffer
+	printer := NewPrinterWithWriter(&buf, FormatTable, false)
+	printer.SetKeyOrder([]string{"C", "MISSING", "A"})
+
+	if err := printer.PrintDotEnv(map[string]string{"A": "1", "B": "2", "C": "3", "D": "4"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "C=\"3\"\nA=\"1\"\nB=\"2\"\nD=\"4\"\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"fmt"
+	"time"
+)
+
+// OperationSummary counts the outcomes of a bulk operation so every bulk
+// command reports its result the same way
+type OperationSummary struct {
+	Created  int
+	Updated  int
+	Skipped  int
+	Failed   int
+	Duration time.Duration
+}
+
+// Total returns the number of items the operation processed
+func (s OperationSummary) Total() int {
+	return s.Created + s.Updated + s.Skipped + s.Failed
+}
+
+// PrintSummary renders a bulk operation summary as a footer line, or as a
+// JSON object when the printer's format is JSON
+func (p *Printer) PrintSummary(summary OperationSummary) error {
+	if p.format == FormatJSON {
+		return p.printJSON(struct {
+			Created    int   `json:"created"`
+			Updated    int   `json:"updated"`
+			Skipped    int   `json:"skipped"`
+			Failed     int   `json:"failed"`
+			DurationMS int64 `json:"duration_ms"`
+		}{
+			Created:    summary.Created,
+			Updated:    summary.Updated,
+			Skipped:    summary.Skipped,
+			Failed:     summary.Failed,
+			DurationMS: summary.Duration.Milliseconds(),
+		})
+	}
+
+	message := fmt.Sprintf(
+		"Summary: %d created, %d updated, %d skipped, %d failed (%s)",
+		summary.Created, summary.Updated, summary.Skipped, summary.Failed,
+		summary.Duration.Round(time.Millisecond),
+	)
+	if summary.Failed > 0 {
+		p.Warning(message)
+	} else {
+		p.Info(message)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"strings"
@@ -11,7 +12,15 @@ import (
 )
 
 // AnnotatedDotEnvParser parses .env files with schema annotations in comments
-type AnnotatedDotEnvParser struct{}
+type AnnotatedDotEnvParser struct {
+	// Interpolate expands ${VAR} and $VAR references in values against keys
+	// defined earlier in the same file. Single-quoted values and \$ stay literal.
+	Interpolate bool
+
+	// UseProcessEnv falls back to the process environment for references that
+	// are not defined earlier in the file (only used when Interpolate is set)
+	UseProcessEnv bool
+}
 
 // NewAnnotatedDotEnvParser creates a new annotated dotenv parser
 func NewAnnotatedDotEnvParser() *AnnotatedDotEnvParser {
@@ -20,20 +29,18 @@ func NewAnnotatedDotEnvParser() *AnnotatedDotEnvParser {
 
 // ParseFile parses an annotated .env file and returns both the values and extracted schema
 func (p *AnnotatedDotEnvParser) ParseFile(path string) (map[string]string, entities.Schema, error) {
-	file, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, entities.Schema{}, fmt.Errorf("failed to open .env file: %w", err)
 	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			// Log the error but don't override the main error
-		}
-	}()
+	if err := entities.CheckEncoding(data); err != nil {
+		return nil, entities.Schema{}, fmt.Errorf("failed to parse .env file %s: %w", path, err)
+	}
 
 	values := make(map[string]string)
 	variables := make(map[string]entities.Variable)
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(entities.TrimBOM(data)))
 	lineNum := 0
 
 	currentVarAnnotations := make(map[string]string)
@@ -67,6 +74,13 @@ func (p *AnnotatedDotEnvParser) ParseFile(path string) (map[string]string, entit
 				return nil, entities.Schema{}, err
 			}
 
+			if p.Interpolate && !isSingleQuoted(line) {
+				value, err = p.interpolate(value, lineNum, values)
+				if err != nil {
+					return nil, entities.Schema{}, err
+				}
+			}
+
 			values[key] = value
 
 			// Create variable definition from annotations
@@ -155,6 +169,107 @@ func (p *AnnotatedDotEnvParser) parseKeyValue(line string, lineNum int) (string,
 	return key, value, nil
 }
 
+// isSingleQuoted reports whether the value of a KEY=VALUE line is wrapped in
+// single quotes, which disables interpolation
+func isSingleQuoted(line string) bool {
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	value := strings.TrimSpace(parts[1])
+	return len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'")
+}
+
+// interpolate expands ${VAR} and $VAR references in value using keys defined
+// earlier in the file, then the process environment if enabled. \$ produces a
+// literal $ and a $ not followed by a name is kept as is.
+func (p *AnnotatedDotEnvParser) interpolate(
+	value string,
+	lineNum int,
+	defined map[string]string,
+) (string, error) {
+	var sb strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+
+		if ch == '\\' && i+1 < len(value) && value[i+1] == '$' {
+			sb.WriteByte('$')
+			i++
+			continue
+		}
+
+		if ch != '$' || i+1 >= len(value) {
+			sb.WriteByte(ch)
+			continue
+		}
+
+		var name string
+		if value[i+1] == '{' {
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("line %d: unterminated variable reference in %q", lineNum, value)
+			}
+			name = value[i+2 : i+2+end]
+			if !isName(name) {
+				return "", fmt.Errorf(
+					"line %d: invalid variable reference ${%s} (only ${NAME} is supported)",
+					lineNum, name,
+				)
+			}
+			i += end + 2
+		} else {
+			end := i + 1
+			for end < len(value) && isNameByte(value[end], end == i+1) {
+				end++
+			}
+			name = value[i+1 : end]
+			if name == "" {
+				sb.WriteByte(ch)
+				continue
+			}
+			i = end - 1
+		}
+
+		resolved, ok := defined[name]
+		if !ok && p.UseProcessEnv {
+			resolved, ok = os.LookupEnv(name)
+		}
+		if !ok {
+			return "", fmt.Errorf("line %d: unresolved variable reference ${%s}", lineNum, name)
+		}
+		sb.WriteString(resolved)
+	}
+
+	return sb.String(), nil
+}
+
+// isName reports whether name is a non-empty variable name
+func isName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isNameByte(name[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNameByte reports whether b can appear in a variable name; digits are not
+// allowed as the first character
+func isNameByte(b byte, first bool) bool {
+	switch {
+	case b == '_', b >= 'A' && b <= 'Z', b >= 'a' && b <= 'z':
+		return true
+	case b >= '0' && b <= '9':
+		return !first
+	default:
+		return false
+	}
+}
+
 // createVariableFromAnnotations creates a Variable definition from comment annotations
 func (p *AnnotatedDotEnvParser) createVariableFromAnnotations(
 	name string,
@@ -186,6 +301,10 @@ func (p *AnnotatedDotEnvParser) createVariableFromAnnotations(
 		variable.Required = strings.ToLower(required) == "true"
 	}
 
+	if secret, exists := annotations["secret"]; exists {
+		variable.Secret = strings.ToLower(secret) == "true"
+	}
+
 	return variable
 }
 
@@ -275,6 +394,12 @@ func (p *AnnotatedDotEnvParser) writeVariableAnnotations(
 			return fmt.Errorf("failed to write required annotation: %w", err)
 		}
 	}
+
+	if variable.Secret {
+		if _, err := fmt.Fprintf(file, "# secret: true\n"); err != nil {
+			return fmt.Errorf("failed to write secret annotation: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseFileInterpolation(t *testing.T) {
+	path := writeEnvFile(t, strings.Join([]string{
+		"HOST=localhost",
+		"PORT=8080",
+		`BASE_URL="https://${HOST}:$PORT/api"`,
+		`PRICE=\$5`,
+		"LITERAL='${HOST}'",
+		"TRAILING=cost$",
+	}, "\n"))
+
+	p := NewAnnotatedDotEnvParser()
+	p.Interpolate = true
+
+	values, _, err := p.ParseFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"BASE_URL": "https://localhost:8080/api",
+		"PRICE":    "$5",
+		"LITERAL":  "${HOST}",
+		"TRAILING": "cost$",
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("%s = %q, want %q", key, values[key], value)
+		}
+	}
+}
+
+func TestParseFileInterpolationDisabledByDefault(t *testing.T) {
+	path := writeEnvFile(t, "HOST=localhost\nURL=http://${HOST}\n")
+
+	values, _, err := NewAnnotatedDotEnvParser().ParseFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["URL"] != "http://${HOST}" {
+		t.Errorf("URL = %q, want literal reference", values["URL"])
+	}
+}
+
+func TestParseFileInterpolationUnresolved(t *testing.T) {
+	path := writeEnvFile(t, "HOST=localhost\nURL=http://${MISSING_HOST_FOR_TEST}\n")
+
+	p := NewAnnotatedDotEnvParser()
+	p.Interpolate = true
+
+	_, _, err := p.ParseFile(path)
+	if err == nil || !strings.Contains(err.Error(), "line 2") ||
+		!strings.Contains(err.Error(), "MISSING_HOST_FOR_TEST") {
+		t.Fatalf("expected unresolved reference error on line 2, got %v", err)
+	}
+}
+
+func TestParseFileInterpolationInvalidReference(t *testing.T) {
+	for _, line := range []string{"URL=${}", "URL=${HOST:-localhost}", "URL=${1HOST}"} {
+		path := writeEnvFile(t, "HOST=localhost\n"+line+"\n")
+
+		p := NewAnnotatedDotEnvParser()
+		p.Interpolate = true
+
+		_, _, err := p.ParseFile(path)
+		if err == nil || !strings.Contains(err.Error(), "invalid variable reference") ||
+			!strings.Contains(err.Error(), "line 2") {
+			t.Errorf("%s: expected invalid reference error on line 2, got %v", line, err)
+		}
+	}
+}
+
+func TestParseFileInterpolationProcessEnv(t *testing.T) {
+	t.Setenv("EE_TEST_REGION", "eu-west-1")
+	path := writeEnvFile(t, "BUCKET=assets-${EE_TEST_REGION}\n")
+
+	p := NewAnnotatedDotEnvParser()
+	p.Interpolate = true
+
+	if _, _, err := p.ParseFile(path); err == nil {
+		t.Fatal("expected error when process environment is not consulted")
+	}
+
+	p.UseProcessEnv = true
+	values, _, err := p.ParseFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["BUCKET"] != "assets-eu-west-1" {
+		t.Errorf("BUCKET = %q, want %q", values["BUCKET"], "assets-eu-west-1")
+	}
+}
+
+func TestParseFileRejectsInvalidUTF8(t *testing.T) {
+	// "caf\xe9" is "café" encoded as latin-1
+	path := writeEnvFile(t, "NAME=ok\nGREETING=caf\xe9\n")
+
+	_, _, err := NewAnnotatedDotEnvParser().ParseFile(path)
+	if err == nil {
+		t.Fatal("expected an error for invalid UTF-8")
+	}
+	if !strings.Contains(err.Error(), "invalid UTF-8 on line 2") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseFileRejectsUTF16(t *testing.T) {
+	path := writeEnvFile(t, "\xff\xfeK\x00=\x00v\x00")
+
+	_, _, err := NewAnnotatedDotEnvParser().ParseFile(path)
+	if err == nil || !strings.Contains(err.Error(), "UTF-16") {
+		t.Fatalf("expected a UTF-16 error, got %v", err)
+	}
+}
+
+func TestParseFileStripsUTF8BOM(t *testing.T) {
+	path := writeEnvFile(t, "\xef\xbb\xbfNAME=café\n")
+
+	values, _, err := NewAnnotatedDotEnvParser().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if values["NAME"] != "café" {
+		t.Errorf("NAME = %q, want %q", values["NAME"], "café")
+	}
+}
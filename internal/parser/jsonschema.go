@@ -0,0 +1,249 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/n1rna/ee-cli/internal/entities"
+)
+
+// JSONSchemaDraft07 is the $schema URI written by ExportJSONSchema
+const JSONSchemaDraft07 = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema is the subset of a draft-07 JSON Schema document that ee
+// schemas map onto: an object whose properties are the variables
+type JSONSchema struct {
+	Schema      string                        `json:"$schema"`
+	Title       string                        `json:"title,omitempty"`
+	Description string                        `json:"description,omitempty"`
+	Type        string                        `json:"type"`
+	Properties  map[string]JSONSchemaProperty `json:"properties"`
+	Required    []string                      `json:"required,omitempty"`
+}
+
+// JSONSchemaProperty describes a single variable in a JSON Schema
+type JSONSchemaProperty struct {
+	Type        string      `json:"type"`
+	Format      string      `json:"format,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Pattern     string      `json:"pattern,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Minimum     *float64    `json:"minimum,omitempty"`
+	Maximum     *float64    `json:"maximum,omitempty"`
+	WriteOnly   bool        `json:"writeOnly,omitempty"`
+}
+
+// ExportJSONSchema converts an ee schema into a draft-07 JSON Schema. Types
+// map to their JSON Schema equivalents (url becomes a string with format uri),
+// defaults are converted to the property's type, and secret variables are
+// marked writeOnly.
+func ExportJSONSchema(schema *entities.Schema) *JSONSchema {
+	doc := &JSONSchema{
+		Schema:      JSONSchemaDraft07,
+		Title:       schema.Title,
+		Description: schema.Description,
+		Type:        "object",
+		Properties:  make(map[string]JSONSchemaProperty, len(schema.Variables)),
+	}
+	if doc.Title == "" {
+		doc.Title = schema.Name
+	}
+
+	for _, variable := range schema.Variables {
+		property := JSONSchemaProperty{
+			Type:      "string",
+			Title:     variable.Title,
+			Pattern:   variable.Regex,
+			Minimum:   variable.Min,
+			Maximum:   variable.Max,
+			WriteOnly: variable.Secret,
+		}
+
+		switch variable.Type {
+		case "number", "integer", "boolean":
+			property.Type = variable.Type
+		case "url":
+			property.Format = "uri"
+		}
+
+		if variable.Default != "" {
+			property.Default = typedDefault(variable.Type, variable.Default)
+		}
+
+		doc.Properties[variable.Name] = property
+		if variable.Required {
+			doc.Required = append(doc.Required, variable.Name)
+		}
+	}
+
+	sort.Strings(doc.Required)
+	return doc
+}
+
+// typedDefault converts a string default to the JSON type of its variable,
+// keeping the string when it does not parse
+func typedDefault(varType, value string) interface{} {
+	switch varType {
+	case "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "integer":
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// supportedPropertyKeywords are the JSON Schema property keywords that
+// ImportJSONSchema maps onto variable fields
+var supportedPropertyKeywords = map[string]bool{
+	"type":        true,
+	"format":      true,
+	"title":       true,
+	"description": true,
+	"pattern":     true,
+	"default":     true,
+	"minimum":     true,
+	"maximum":     true,
+	"writeOnly":   true,
+}
+
+// ImportJSONSchema converts a JSON Schema object into an ee schema. Each entry
+// of properties becomes a variable: string with format uri maps to url,
+// pattern to regex, minimum/maximum to min/max, writeOnly to secret, and
+// names listed in required are required. Constructs with no ee equivalent
+// (enum, nested objects, arrays, ...) are skipped and reported as warnings
+// instead of failing the import.
+func ImportJSONSchema(data []byte, name string) (*entities.Schema, []string, error) {
+	var doc struct {
+		Title       string                            `json:"title"`
+		Description string                            `json:"description"`
+		Properties  map[string]map[string]interface{} `json:"properties"`
+		Required    []string                          `json:"required"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JSON Schema: %w", err)
+	}
+	if len(doc.Properties) == 0 {
+		return nil, nil, fmt.Errorf("JSON Schema has no properties to import")
+	}
+
+	schema := &entities.Schema{
+		Name:        name,
+		Title:       doc.Title,
+		Description: doc.Description,
+	}
+
+	required := make(map[string]bool, len(doc.Required))
+	for _, key := range doc.Required {
+		required[key] = true
+	}
+
+	names := make([]string, 0, len(doc.Properties))
+	for key := range doc.Properties {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, key := range names {
+		variable, propertyWarnings := importJSONSchemaProperty(key, doc.Properties[key])
+		variable.Required = required[key]
+		schema.Variables = append(schema.Variables, variable)
+		warnings = append(warnings, propertyWarnings...)
+	}
+
+	for _, key := range doc.Required {
+		if _, exists := doc.Properties[key]; !exists {
+			warnings = append(warnings, fmt.Sprintf("required name %q has no property and was ignored", key))
+		}
+	}
+
+	return schema, warnings, nil
+}
+
+// importJSONSchemaProperty converts a single JSON Schema property
+func importJSONSchemaProperty(name string, property map[string]interface{}) (entities.Variable, []string) {
+	variable := entities.Variable{Name: name, Type: "string"}
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf("property %s: ", name)+fmt.Sprintf(format, args...))
+	}
+
+	switch propertyType := property["type"].(type) {
+	case nil:
+	case string:
+		switch propertyType {
+		case "string":
+		case "number", "integer", "boolean":
+			variable.Type = propertyType
+		default:
+			warn("type %q is not supported, imported as string", propertyType)
+		}
+	default:
+		warn("only a single type is supported, imported as string")
+	}
+
+	if format, ok := property["format"].(string); ok {
+		if variable.Type == "string" && (format == "uri" || format == "url") {
+			variable.Type = "url"
+		} else {
+			warn("format %q is not supported and was ignored", format)
+		}
+	}
+
+	if title, ok := property["title"].(string); ok {
+		variable.Title = title
+	} else if description, ok := property["description"].(string); ok {
+		variable.Title = description
+	}
+
+	if pattern, ok := property["pattern"].(string); ok {
+		variable.Regex = pattern
+	}
+
+	if value, exists := property["default"]; exists {
+		switch v := value.(type) {
+		case string:
+			variable.Default = v
+		case bool:
+			variable.Default = strconv.FormatBool(v)
+		case float64:
+			variable.Default = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			warn("default of type %T is not supported and was ignored", value)
+		}
+	}
+
+	if minimum, ok := property["minimum"].(float64); ok {
+		variable.Min = &minimum
+	}
+	if maximum, ok := property["maximum"].(float64); ok {
+		variable.Max = &maximum
+	}
+	if writeOnly, ok := property["writeOnly"].(bool); ok {
+		variable.Secret = writeOnly
+	}
+
+	var unsupported []string
+	for keyword := range property {
+		if !supportedPropertyKeywords[keyword] {
+			unsupported = append(unsupported, keyword)
+		}
+	}
+	sort.Strings(unsupported)
+	for _, keyword := range unsupported {
+		warn("keyword %q is not supported and was ignored", keyword)
+	}
+
+	return variable, warnings
+}
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/n1rna/ee-cli/internal/entities"
+)
+
+func TestExportJSONSchema(t *testing.T) {
+	minPort, maxPort := 1.0, 65535.0
+	schema := &entities.Schema{
+		Name:        "api",
+		Description: "API settings",
+		Variables: []entities.Variable{
+			{Name: "PORT", Type: "integer", Default: "3000", Min: &minPort, Max: &maxPort},
+			{Name: "DEBUG", Type: "boolean", Default: "false"},
+			{Name: "API_URL", Type: "url", Required: true, Title: "API base URL"},
+			{Name: "API_KEY", Type: "string", Required: true, Regex: "^[a-z0-9]+$", Secret: true},
+		},
+	}
+
+	data, err := json.MarshalIndent(ExportJSONSchema(schema), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "api",
+  "description": "API settings",
+  "type": "object",
+  "properties": {
+    "API_KEY": {
+      "type": "string",
+      "pattern": "^[a-z0-9]+$",
+      "writeOnly": true
+    },
+    "API_URL": {
+      "type": "string",
+      "format": "uri",
+      "title": "API base URL"
+    },
+    "DEBUG": {
+      "type": "boolean",
+      "default": false
+    },
+    "PORT": {
+      "type": "integer",
+      "default": 3000,
+      "minimum": 1,
+      "maximum": 65535
+    }
+  },
+  "required": [
+    "API_KEY",
+    "API_URL"
+  ]
+}`
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestImportJSONSchema(t *testing.T) {
+	data := []byte(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "API",
+  "type": "object",
+  "properties": {
+    "API_URL": {"type": "string", "format": "uri", "description": "Base URL"},
+    "PORT": {"type": "integer", "default": 3000, "minimum": 1},
+    "DEBUG": {"type": "boolean", "default": false},
+    "API_KEY": {"type": "string", "pattern": "^[a-z]+$", "writeOnly": true},
+    "LEVEL": {"type": "string", "enum": ["debug", "info"]},
+    "TAGS": {"type": "array"}
+  },
+  "required": ["API_URL", "API_KEY", "MISSING"]
+}`)
+
+	schema, warnings, err := ImportJSONSchema(data, "api")
+	if err != nil {
+		t.Fatalf("ImportJSONSchema failed: %v", err)
+	}
+
+	variables := make(map[string]entities.Variable)
+	for _, v := range schema.Variables {
+		variables[v.Name] = v
+	}
+
+	if v := variables["API_URL"]; v.Type != "url" || !v.Required || v.Title != "Base URL" {
+		t.Errorf("API_URL = %+v", v)
+	}
+	if v := variables["PORT"]; v.Type != "integer" || v.Default != "3000" || v.Min == nil || *v.Min != 1 {
+		t.Errorf("PORT = %+v", v)
+	}
+	if v := variables["DEBUG"]; v.Type != "boolean" || v.Default != "false" {
+		t.Errorf("DEBUG = %+v", v)
+	}
+	if v := variables["API_KEY"]; v.Regex != "^[a-z]+$" || !v.Secret || !v.Required {
+		t.Errorf("API_KEY = %+v", v)
+	}
+	if v := variables["TAGS"]; v.Type != "string" {
+		t.Errorf("TAGS = %+v", v)
+	}
+
+	wantWarnings := []string{
+		`property LEVEL: keyword "enum" is not supported and was ignored`,
+		`property TAGS: type "array" is not supported, imported as string`,
+		`required name "MISSING" has no property and was ignored`,
+	}
+	if len(warnings) != len(wantWarnings) {
+		t.Fatalf("warnings = %q", warnings)
+	}
+	for i, want := range wantWarnings {
+		if warnings[i] != want {
+			t.Errorf("warning %d = %q, want %q", i, warnings[i], want)
+		}
+	}
+}
+
+func TestJSONSchemaRoundTrip(t *testing.T) {
+	original := &entities.Schema{
+		Name: "app",
+		Variables: []entities.Variable{
+			{Name: "DATABASE_URL", Type: "url", Required: true},
+			{Name: "WORKERS", Type: "integer", Default: "4"},
+		},
+	}
+
+	data, err := json.Marshal(ExportJSONSchema(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, warnings, err := ImportJSONSchema(data, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %q", warnings)
+	}
+	if !reflect.DeepEqual(imported.Variables, original.Variables) {
+		t.Errorf("round trip changed variables: %+v", imported.Variables)
+	}
+}
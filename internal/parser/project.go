@@ -8,6 +8,8 @@ import (
 
 	"github.com/n1rna/ee-cli/internal/config"
 	"github.com/n1rna/ee-cli/internal/entities"
+	"github.com/n1rna/ee-cli/internal/fsutil"
+	"github.com/n1rna/ee-cli/internal/logger"
 	"github.com/n1rna/ee-cli/internal/origin"
 )
 
@@ -17,13 +19,21 @@ type ProjectConfig struct {
 	Schema       ProjectConfigSchema              `json:"schema"`            // Schema definition or reference
 	Environments map[string]EnvironmentDefinition `json:"environments"`      // Environment configurations
 	Origins      map[string]origin.Config         `json:"origins,omitempty"` // Remote origin configurations
+
+	// LocalOverrides layers a gitignored <file>.local over each .env file in
+	// apply and verify (e.g. .env.production.local over .env.production)
+	LocalOverrides bool `json:"local_overrides,omitempty"`
 }
 
 // ProjectConfigSchema defines the schema for the project, either inline or by reference
 type ProjectConfigSchema struct {
-	Ref       string                       `json:"ref,omitempty"`       // Reference to remote/local schema
-	Extends   []string                     `json:"extends,omitempty"`   // Schema inheritance
-	Variables map[string]entities.Variable `json:"variables,omitempty"` // Inline variable definitions
+	Ref         string                       `json:"ref,omitempty"`         // Reference to remote/local schema
+	Title       string                       `json:"title,omitempty"`       // Display title for inline schemas
+	Description string                       `json:"description,omitempty"` // Description for inline schemas
+	Extends     []string                     `json:"extends,omitempty"`     // Schema inheritance
+	Variables   map[string]entities.Variable `json:"variables,omitempty"`   // Inline variable definitions
+
+	ExpandDefaults bool `json:"expand_defaults,omitempty"` // Expand ${NAME:-fallback} in defaults
 }
 
 // EnvironmentDefinition defines how an environment is configured
@@ -66,6 +76,7 @@ func LoadProjectConfigFromPath(path string) (*ProjectConfig, error) {
 		return nil, fmt.Errorf(".ee file missing required 'environments' field")
 	}
 
+	logger.Debugf("loaded project config %s (%d environments)", path, len(config.Environments))
 	return &config, nil
 }
 
@@ -76,7 +87,7 @@ func SaveProjectConfig(config *ProjectConfig, path string) error {
 		return fmt.Errorf("failed to marshal project config: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	if err := fsutil.WriteFileAtomic(path, data, 0o644); err != nil {
 		return fmt.Errorf("failed to write .ee file: %w", err)
 	}
 
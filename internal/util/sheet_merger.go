@@ -3,8 +3,12 @@ package util
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+
+	"github.com/n1rna/ee-cli/internal/entities"
+	"github.com/n1rna/ee-cli/internal/logger"
 )
 
 // EnvResolver handles resolving environment definitions to key-value pairs
@@ -20,6 +24,16 @@ func NewEnvResolver() *EnvResolver {
 func (r *EnvResolver) MergeEnvironment(
 	env EnvironmentSources,
 ) (map[string]string, error) {
+	values, _, err := r.MergeEnvironmentWithProvenance(env)
+	return values, err
+}
+
+// MergeEnvironmentWithProvenance merges all sources for the given environment
+// definition and also returns, for each key, a label naming the source its
+// final value came from (the .env file path, or "inline source N")
+func (r *EnvResolver) MergeEnvironmentWithProvenance(
+	env EnvironmentSources,
+) (map[string]string, map[string]string, error) {
 	var refs []interface{}
 
 	// Handle single env file reference
@@ -37,35 +51,61 @@ func (r *EnvResolver) MergeEnvironment(
 		refs = append(refs, env.Sources...)
 	}
 
-	if len(refs) == 0 {
-		return map[string]string{}, nil
+	// Local overrides go last so they win over every committed source
+	if env.LocalOverrides {
+		for _, ref := range refs {
+			if path, ok := ref.(string); ok {
+				if local, exists := LocalOverridePath(path); exists {
+					refs = append(refs, local)
+				}
+			}
+		}
 	}
 
 	// Merge sources in order (later sources override earlier ones)
 	result := make(map[string]string)
+	provenance := make(map[string]string)
 	for i, ref := range refs {
-		values, err := r.resolveReference(ref)
+		values, err := r.resolveReference(ref, env.ParseFile)
 		if err != nil {
-			return nil, fmt.Errorf(
+			return nil, nil, fmt.Errorf(
 				"failed to resolve source reference %d: %w",
 				i, err,
 			)
 		}
 
+		label := fmt.Sprintf("inline source %d", i)
+		if path, ok := ref.(string); ok {
+			label = path
+		}
+
 		for key, value := range values {
 			result[key] = value
+			provenance[key] = label
 		}
 	}
 
-	return result, nil
+	for key, value := range env.Defaults {
+		if _, ok := result[key]; !ok {
+			result[key] = entities.ExpandDefault(value, os.LookupEnv)
+			provenance[key] = DefaultSource
+		}
+	}
+
+	return result, provenance, nil
 }
 
-// resolveReference resolves a single source reference to its key-value pairs
+// resolveReference resolves a single source reference to its key-value pairs,
+// reading .env files with parseFile when it is set
 func (r *EnvResolver) resolveReference(
 	ref interface{},
+	parseFile func(path string) (map[string]string, error),
 ) (map[string]string, error) {
 	switch v := ref.(type) {
 	case string:
+		if parseFile != nil {
+			return parseFile(v)
+		}
 		return r.resolveDotEnvFile(v)
 	case map[string]interface{}:
 		return r.resolveInlineObject(v)
@@ -114,13 +154,35 @@ func (r *EnvResolver) resolveInlineObject(
 func (r *EnvResolver) parseDotEnvFile(
 	path string,
 ) (map[string]string, error) {
-	content, err := os.ReadFile(path)
+	file, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read .env file: %w", err)
 	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	logger.Debugf("reading .env file %s", path)
+	return r.ParseDotEnv(file, ".env file "+path)
+}
+
+// ParseDotEnv parses KEY=VALUE lines from a reader. The source name is only
+// used in error messages (e.g. ".env file .env.local" or "stdin").
+func (r *EnvResolver) ParseDotEnv(
+	reader io.Reader,
+	source string,
+) (map[string]string, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	if err := entities.CheckEncoding(content); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+	}
 
 	result := make(map[string]string)
-	lines := strings.Split(string(content), "\n")
+	lines := strings.Split(string(entities.TrimBOM(content)), "\n")
 
 	for lineNum, line := range lines {
 		line = strings.TrimSpace(line)
@@ -134,8 +196,8 @@ func (r *EnvResolver) parseDotEnvFile(
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
 			return nil, fmt.Errorf(
-				"invalid line %d in .env file %s: %s",
-				lineNum+1, path, line,
+				"invalid line %d in %s: %s",
+				lineNum+1, source, line,
 			)
 		}
 
@@ -154,8 +216,8 @@ func (r *EnvResolver) parseDotEnvFile(
 
 		if key == "" {
 			return nil, fmt.Errorf(
-				"empty variable name on line %d in .env file %s",
-				lineNum+1, path,
+				"empty variable name on line %d in %s",
+				lineNum+1, source,
 			)
 		}
 
@@ -171,4 +233,32 @@ type EnvironmentSources struct {
 	Env     string        // Single .env file reference
 	Sources []interface{} // Multiple sources (.env files, inline objects)
 	Sheets  []string      // List of .env file paths
+
+	// LocalOverrides layers each file's <path>.local override on top when present
+	LocalOverrides bool
+
+	// Defaults fill keys that no source sets. ${NAME} and ${NAME:-fallback}
+	// references in them are expanded against the host environment.
+	Defaults map[string]string
+
+	// ParseFile, when set, reads .env file references instead of the built-in
+	// parser (e.g. to expand ${VAR} references)
+	ParseFile func(path string) (map[string]string, error)
+}
+
+// DefaultSource is the provenance label of values filled from Defaults
+const DefaultSource = "schema default"
+
+// LocalOverrideSuffix names the gitignored override of an .env file
+// (.env.production -> .env.production.local)
+const LocalOverrideSuffix = ".local"
+
+// LocalOverridePath returns the local override path for an .env file and
+// whether that override exists
+func LocalOverridePath(path string) (string, bool) {
+	local := path + LocalOverrideSuffix
+	if _, err := os.Stat(local); err != nil {
+		return local, false
+	}
+	return local, true
 }